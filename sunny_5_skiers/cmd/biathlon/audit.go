@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// auditFlag is the destination file of the hash-chained audit log.
+type auditFlag struct {
+	path string
+}
+
+// register adds the audit flag to the flag set.
+func (a *auditFlag) register(flags *flag.FlagSet) {
+	flags.StringVar(&a.path, "audit", "", "hash-chained audit log destination file (disabled if empty)")
+}
+
+// create creates the audit log file, the writer is nil if the audit log
+// is disabled. The returned function closes the file.
+func (a *auditFlag) create() (io.Writer, func()) {
+	if a.path == "" {
+		return nil, func() {}
+	}
+
+	f, err := os.Create(a.path)
+	if err != nil {
+		log.Fatalf("cannot create audit log: %s", err)
+	}
+	return f, func() { f.Close() }
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"biathlon/config"
+	"biathlon/internal/app"
+	"flag"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+)
+
+func runChart(args []string) {
+	flags := flag.NewFlagSet("chart", flag.ExitOnError)
+	events := flags.String("events", "events", "incoming events file")
+	dir := flags.String("dir", "charts", "directory of the SVG charts")
+	flags.Parse(args)
+
+	cfg, err := config.New()
+	if err != nil {
+		log.Fatalf("cannot get application config: %s", err)
+	}
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("cannot initialize logger: %s", err)
+	}
+
+	f, err := os.Open(*events)
+	if err != nil {
+		log.Fatalf("cannot open events file: %s", err)
+	}
+	defer f.Close()
+
+	paths, err := app.Charts(logger, cfg, f, *dir)
+	if err != nil {
+		log.Fatalf("cannot render charts: %s", err)
+	}
+
+	for _, path := range paths {
+		fmt.Println(path)
+	}
+}
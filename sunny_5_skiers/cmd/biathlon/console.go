@@ -0,0 +1,51 @@
+package main
+
+import (
+	"biathlon/config"
+	"biathlon/internal/console"
+	"flag"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+)
+
+func runConsole(args []string) {
+	flags := flag.NewFlagSet("console", flag.ExitOnError)
+	events := flags.String("events", "events", "incoming events file the committed events are appended to")
+	logOutput := flags.String("log-output", "", "application log destination file (disabled if empty)")
+	flags.Parse(args)
+
+	cfg, err := config.New()
+	if err != nil {
+		log.Fatalf("cannot get application config: %s", err)
+	}
+
+	logger := zap.NewNop()
+	if *logOutput != "" {
+		logCfg := zap.NewProductionConfig()
+		logCfg.OutputPaths = []string{*logOutput}
+		logger, err = logCfg.Build()
+		if err != nil {
+			log.Fatalf("cannot initialize logger: %s", err)
+		}
+	}
+
+	f, err := os.OpenFile(*events, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		log.Fatalf("cannot open events file: %s", err)
+	}
+	defer f.Close()
+
+	c := console.New(logger, cfg, os.Stdout, f, time.Now)
+	err = c.Load(f)
+	if err != nil {
+		log.Fatalf("cannot read events: %s", err)
+	}
+
+	err = c.Run(os.Stdin)
+	if err != nil {
+		log.Fatalf("console error: %s", err)
+	}
+}
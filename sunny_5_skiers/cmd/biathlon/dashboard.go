@@ -0,0 +1,93 @@
+package main
+
+import (
+	"biathlon/config"
+	"biathlon/internal/app"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+)
+
+// follower reads a file that is still being written, waiting for more
+// data at its end like tail -f.
+type follower struct {
+	r        io.Reader
+	interval time.Duration
+}
+
+func (f *follower) Read(p []byte) (int, error) {
+	for {
+		n, err := f.r.Read(p)
+		if n > 0 || !errors.Is(err, io.EOF) {
+			return n, err
+		}
+		time.Sleep(f.interval)
+	}
+}
+
+// terminalSize returns the size from the COLUMNS and LINES environment
+// variables or the fallback.
+func terminalSize(name string, fallback int) int {
+	if n, err := strconv.Atoi(os.Getenv(name)); err == nil && n > 0 {
+		return n
+	}
+	return fallback
+}
+
+func runDashboard(args []string) {
+	flags := flag.NewFlagSet("dashboard", flag.ExitOnError)
+	events := flags.String("events", "events", "incoming events file, - for stdin")
+	follow := flags.Bool("follow", false, "keep waiting for events appended to the file")
+	refresh := flags.Duration("refresh", 100*time.Millisecond, "shortest interval between redraws, 0 redraws after every event")
+	delay := flags.Duration("delay", 0, "delay before every event to replay a recorded race")
+	width := flags.Int("width", terminalSize("COLUMNS", 100), "screen width in characters")
+	height := flags.Int("height", terminalSize("LINES", 40), "screen height in lines")
+	logOutput := flags.String("log-output", "", "application log destination file (disabled if empty)")
+	flags.Parse(args)
+
+	cfg, err := config.New()
+	if err != nil {
+		log.Fatalf("cannot get application config: %s", err)
+	}
+
+	logger := zap.NewNop()
+	if *logOutput != "" {
+		logCfg := zap.NewProductionConfig()
+		logCfg.OutputPaths = []string{*logOutput}
+		logger, err = logCfg.Build()
+		if err != nil {
+			log.Fatalf("cannot initialize logger: %s", err)
+		}
+	}
+
+	var input io.Reader = os.Stdin
+	if *events != "-" {
+		f, err := os.Open(*events)
+		if err != nil {
+			log.Fatalf("cannot open events file: %s", err)
+		}
+		defer f.Close()
+		input = f
+	}
+	if *follow {
+		input = &follower{r: input, interval: 250 * time.Millisecond}
+	}
+
+	err = app.Dashboard(logger, cfg, app.DashboardOptions{
+		Input:   input,
+		Output:  os.Stdout,
+		Width:   *width,
+		Height:  *height,
+		Refresh: *refresh,
+		Delay:   *delay,
+	})
+	if err != nil {
+		log.Fatalf("dashboard error: %s", err)
+	}
+}
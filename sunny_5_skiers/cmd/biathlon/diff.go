@@ -0,0 +1,81 @@
+package main
+
+import (
+	"biathlon/config"
+	"biathlon/internal/app"
+	"biathlon/internal/results"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+)
+
+// readRows reads a results JSON file or processes an events file with
+// the config file. Events files start with a bracket too but are never
+// valid JSON.
+func readRows(logger *zap.Logger, path, configPath string) ([]results.Row, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if json.Valid(data) {
+		return results.ReadRows(path)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return app.Rows(logger, cfg, bytes.NewReader(data))
+}
+
+func runDiff(args []string) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	configA := flags.String("config-a", "config.json", "config of the first events file")
+	configB := flags.String("config-b", "config.json", "config of the second events file")
+	asJSON := flags.Bool("json", false, "print the changes as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: biathlon diff [flags] <events or results> <events or results>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("cannot initialize logger: %s", err)
+	}
+
+	before, err := readRows(logger, flags.Arg(0), *configA)
+	if err != nil {
+		log.Fatalf("cannot read %s: %s", flags.Arg(0), err)
+	}
+
+	after, err := readRows(logger, flags.Arg(1), *configB)
+	if err != nil {
+		log.Fatalf("cannot read %s: %s", flags.Arg(1), err)
+	}
+
+	changes := results.Diff(before, after)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(changes)
+	} else if len(changes) == 0 {
+		_, err = fmt.Println("no results changed")
+	} else {
+		err = results.WriteDiff(os.Stdout, changes)
+	}
+	if err != nil {
+		log.Fatalf("cannot write changes: %s", err)
+	}
+}
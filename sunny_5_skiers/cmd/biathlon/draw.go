@@ -0,0 +1,72 @@
+package main
+
+import (
+	"biathlon/config"
+	"biathlon/internal/draw"
+	"biathlon/internal/util"
+	"flag"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func runDraw(args []string) {
+	flags := flag.NewFlagSet("draw", flag.ExitOnError)
+	rosterPath := flags.String("roster", "roster", "roster file with \"competitorID [group]\" lines")
+	seed := flags.Uint64("seed", 0, "random seed of the draw")
+	eventsOutput := flags.String("events-output", "draw.events", "destination file for the event 2 stream")
+	at := flags.String("at", "", "timestamp of the draw events (config start if empty)")
+	flags.Parse(args)
+
+	cfg, err := config.New()
+	if err != nil {
+		log.Fatalf("cannot get application config: %s", err)
+	}
+
+	start, err := util.ConvertToTimestamp(cfg.Start)
+	if err != nil {
+		log.Fatalf("cannot parse start time: %s", err)
+	}
+
+	delta, err := util.ConvertToDuration(cfg.StartDelta)
+	if err != nil {
+		log.Fatalf("cannot parse start delta: %s", err)
+	}
+
+	drawTime := start
+	if *at != "" {
+		drawTime, err = util.ConvertToTimestamp(*at)
+		if err != nil {
+			log.Fatalf("cannot parse draw time: %s", err)
+		}
+	}
+
+	f, err := os.Open(*rosterPath)
+	if err != nil {
+		log.Fatalf("cannot open roster: %s", err)
+	}
+	defer f.Close()
+
+	roster, err := draw.ReadRoster(f)
+	if err != nil {
+		log.Fatalf("cannot read roster: %s", err)
+	}
+
+	slots := draw.Draw(roster, start, delta, *seed)
+
+	err = draw.WriteStartList(os.Stdout, slots)
+	if err != nil {
+		log.Fatalf("cannot write start list: %s", err)
+	}
+
+	out, err := os.Create(*eventsOutput)
+	if err != nil {
+		log.Fatalf("cannot create events output: %s", err)
+	}
+	defer out.Close()
+
+	err = draw.WriteEvents(out, slots, drawTime)
+	if err != nil {
+		log.Fatalf("cannot write draw events: %s", err)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"biathlon/internal/validator"
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// editList collects -retract, -replace and -insert flags in the command
+// line order and the -edit-reason recorded with all of them.
+type editList struct {
+	edits  []validator.Edit
+	reason string
+}
+
+func (l *editList) String() string {
+	return fmt.Sprint(len(l.edits), " edits")
+}
+
+func (l *editList) retract(value string) error {
+	line, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+
+	l.edits = append(l.edits, validator.Edit{Line: line})
+	return nil
+}
+
+func (l *editList) replace(value string) error {
+	lineValue, event, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(event) == "" {
+		return errIncorrectReplacement
+	}
+
+	line, err := strconv.Atoi(lineValue)
+	if err != nil {
+		return err
+	}
+
+	l.edits = append(l.edits, validator.Edit{Line: line, Event: event})
+	return nil
+}
+
+func (l *editList) insert(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return errIncorrectInsertion
+	}
+
+	l.edits = append(l.edits, validator.Edit{Event: value})
+	return nil
+}
+
+// register adds the edit flags to the flag set.
+func (l *editList) register(flags *flag.FlagSet) {
+	flags.Func("retract", "retract the event on the source line (repeatable)", l.retract)
+	flags.Func("replace", "replace the event on the source line, as <line>=<event> (repeatable)", l.replace)
+	flags.Func("insert", "insert the event by its timestamp (repeatable)", l.insert)
+	flags.StringVar(&l.reason, "edit-reason", "", "reason recorded in the audit log with the edits")
+}
+
+// list returns the edits with the reason.
+func (l *editList) list() []validator.Edit {
+	edits := slices.Clone(l.edits)
+	for i := range edits {
+		edits[i].Reason = l.reason
+	}
+	return edits
+}
+
+var (
+	errIncorrectReplacement = errors.New("replacement must be <line>=<event>")
+	errIncorrectInsertion   = errors.New("inserted event must not be empty")
+)
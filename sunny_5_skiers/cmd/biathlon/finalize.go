@@ -0,0 +1,59 @@
+package main
+
+import (
+	"biathlon/config"
+	"biathlon/internal/app"
+	"biathlon/internal/util"
+	"flag"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+)
+
+func runFinalize(args []string) {
+	flags := flag.NewFlagSet("finalize", flag.ExitOnError)
+	dir := flags.String("dir", "results", "directory of the official results versions")
+	now := flags.String("now", "", "race clock time (wall clock if empty)")
+	reason := flags.String("reason", "", "reason of a revision of already official results")
+	force := flags.Bool("force", false, "finalize before the protest window is closed")
+	var auditOutput auditFlag
+	auditOutput.register(flags)
+	flags.Parse(args)
+
+	cfg, err := config.New()
+	if err != nil {
+		log.Fatalf("cannot get application config: %s", err)
+	}
+
+	clock, err := util.ConvertToTimestamp(util.FormatTimestamp(time.Now()))
+	if *now != "" {
+		clock, err = util.ConvertToTimestamp(*now)
+	}
+	if err != nil {
+		log.Fatalf("cannot parse race clock: %s", err)
+	}
+
+	audit, closeAudit := auditOutput.create()
+	defer closeAudit()
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("cannot initialize logger: %s", err)
+	}
+
+	err = app.Finalize(logger, cfg, app.FinalizeOptions{
+		Dir:    *dir,
+		Key:    []byte(os.Getenv("BIATHLON_SIGNING_KEY")),
+		Clock:  clock,
+		Reason: *reason,
+		Force:  *force,
+		Output: os.Stdout,
+
+		AuditOutput: audit,
+	})
+	if err != nil {
+		log.Fatalf("cannot finalize results: %s", err)
+	}
+}
@@ -3,18 +3,103 @@ package main
 import (
 	"biathlon/config"
 	"biathlon/internal/app"
+	"biathlon/internal/eventlog"
+	"flag"
+	"io"
+	"os"
 
 	log "github.com/sirupsen/logrus"
 	"go.uber.org/zap"
 )
 
+var commands = map[string]func(args []string){
+	"chart":     runChart,
+	"console":   runConsole,
+	"dashboard": runDashboard,
+	"draw":      runDraw,
+	"finalize":  runFinalize,
+	"diff":      runDiff,
+	"predict":   runPredict,
+	"serve":     runServe,
+	"shooting":  runShooting,
+	"simulate":  runSimulate,
+	"verify":    runVerify,
+	"whatif":    runWhatIf,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			command(os.Args[2:])
+			return
+		}
+	}
+	runRace(os.Args[1:])
+}
+
+func runRace(args []string) {
+	flags := flag.NewFlagSet("biathlon", flag.ExitOnError)
+	logFormat := flags.String("log-format", string(eventlog.FormatText), "event log format: text or jsonl")
+	logOutput := flags.String("log-output", "", "event log destination file (stdout if empty)")
+	splits := flags.Bool("splits", false, "print virtual rankings at every timing point")
+	projections := flags.Bool("projections", false, "print projected results of competitors on the course")
+	courseTimes := flags.Bool("course-times", false, "print rankings by course time without range and penalty loops")
+	var auditOutput auditFlag
+	auditOutput.register(flags)
+	resultsOutput := flags.String("results-output", "", "results JSON destination file (disabled if empty)")
+	traceOutput := flags.String("trace", "", "span export destination file (disabled if empty)")
+	var edits editList
+	edits.register(flags)
+	flags.Parse(args)
+
+	if *traceOutput == "-" {
+		log.Fatalf("cannot export spans to stdout: it carries the event log and results")
+	}
+
 	cfg, err := config.New()
 
 	if err != nil {
 		log.Fatalf("cannot get application config: %s", err)
 	}
 
+	format, err := eventlog.ParseFormat(*logFormat)
+	if err != nil {
+		log.Fatalf("invalid log format: %s", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *logOutput != "" {
+		f, err := os.Create(*logOutput)
+		if err != nil {
+			log.Fatalf("cannot create log output: %s", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	audit, closeAudit := auditOutput.create()
+	defer closeAudit()
+
+	var resultsFile io.Writer
+	if *resultsOutput != "" {
+		f, err := os.Create(*resultsOutput)
+		if err != nil {
+			log.Fatalf("cannot create results output: %s", err)
+		}
+		defer f.Close()
+		resultsFile = f
+	}
+
+	var trace io.Writer
+	if *traceOutput != "" {
+		f, err := os.Create(*traceOutput)
+		if err != nil {
+			log.Fatalf("cannot create trace output: %s", err)
+		}
+		defer f.Close()
+		trace = f
+	}
+
 	var logger *zap.Logger
 	logger, err = zap.NewProduction()
 
@@ -22,7 +107,21 @@ func main() {
 		log.Fatalf("cannot initialize logger: %s", err)
 	}
 
-	err = app.Run(logger, cfg)
+	err = app.Run(logger, cfg, app.Options{
+		LogFormat: format,
+		LogOutput: out,
+		Output:    os.Stdout,
+		Splits:    *splits,
+
+		Projections: *projections,
+		CourseTimes: *courseTimes,
+
+		AuditOutput: audit,
+		Edits:       edits.list(),
+
+		ResultsOutput: resultsFile,
+		TraceOutput:   trace,
+	})
 	if err != nil {
 		log.Fatalf("processing stage error: %s", err)
 	}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"biathlon/config"
+	"biathlon/internal/predict"
+	"biathlon/internal/simulate"
+	"encoding/json"
+	"flag"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// readAthletes reads the athlete profiles file or generates a field of
+// random athletes if the path is empty.
+func readAthletes(path string, competitors int, seed uint64) ([]simulate.Athlete, error) {
+	if path == "" {
+		return simulate.Athletes(competitors, seed)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return simulate.ReadAthletes(f)
+}
+
+func runPredict(args []string) {
+	flags := flag.NewFlagSet("predict", flag.ExitOnError)
+	athletesPath := flags.String("athletes", "", "JSON file of athlete profiles (random field if empty)")
+	competitors := flags.Int("competitors", 30, "number of random athletes without an athletes file")
+	runs := flags.Int("runs", 1000, "number of virtual races")
+	seed := flags.Uint64("seed", 0, "random seed of the virtual races")
+	dnfRate := flags.Float64("dnf-rate", 0.02, "probability of a competitor not finishing")
+	output := flags.String("output", "prediction.json", "JSON report destination file")
+	flags.Parse(args)
+
+	cfg, err := config.New()
+	if err != nil {
+		log.Fatalf("cannot get application config: %s", err)
+	}
+
+	athletes, err := readAthletes(*athletesPath, *competitors, *seed)
+	if err != nil {
+		log.Fatalf("cannot read athletes: %s", err)
+	}
+
+	report, err := predict.Predict(cfg, athletes, predict.Options{Runs: *runs, Seed: *seed, DNFRate: *dnfRate})
+	if err != nil {
+		log.Fatalf("cannot predict race: %s", err)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		log.Fatalf("cannot create report output: %s", err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(report)
+	if err != nil {
+		log.Fatalf("cannot write report: %s", err)
+	}
+
+	err = report.WriteSummary(os.Stdout)
+	if err != nil {
+		log.Fatalf("cannot write summary: %s", err)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"biathlon/config"
+	"biathlon/internal/app"
+	"flag"
+	"io"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+)
+
+func runServe(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":9090", "listen address of the /metrics endpoint")
+	events := flags.String("events", "events", "incoming events file, - for stdin")
+	follow := flags.Bool("follow", false, "keep waiting for events appended to the file")
+	flags.Parse(args)
+
+	cfg, err := config.New()
+	if err != nil {
+		log.Fatalf("cannot get application config: %s", err)
+	}
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("cannot initialize logger: %s", err)
+	}
+
+	var input io.Reader = os.Stdin
+	if *events != "-" {
+		f, err := os.Open(*events)
+		if err != nil {
+			log.Fatalf("cannot open events file: %s", err)
+		}
+		defer f.Close()
+		input = f
+	}
+	if *follow {
+		input = &follower{r: input, interval: 250 * time.Millisecond}
+	}
+
+	err = app.Serve(logger, cfg, app.ServeOptions{Input: input, Addr: *addr})
+	if err != nil {
+		log.Fatalf("metrics server error: %s", err)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"biathlon/config"
+	"biathlon/internal/analytics"
+	"biathlon/internal/app"
+	"encoding/json"
+	"flag"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+)
+
+func runShooting(args []string) {
+	flags := flag.NewFlagSet("shooting", flag.ExitOnError)
+	events := flags.String("events", "events", "incoming events file")
+	asJSON := flags.Bool("json", false, "print the analytics as JSON")
+	flags.Parse(args)
+
+	cfg, err := config.New()
+	if err != nil {
+		log.Fatalf("cannot get application config: %s", err)
+	}
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("cannot initialize logger: %s", err)
+	}
+
+	f, err := os.Open(*events)
+	if err != nil {
+		log.Fatalf("cannot open events file: %s", err)
+	}
+	defer f.Close()
+
+	standings, err := app.Standings(logger, cfg, f)
+	if err != nil {
+		log.Fatalf("cannot read events: %s", err)
+	}
+
+	report := analytics.Shooting(standings)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		log.Fatalf("cannot write shooting analytics: %s", err)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"biathlon/config"
+	"biathlon/internal/simulate"
+	"bufio"
+	"flag"
+	"io"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func runSimulate(args []string) {
+	flags := flag.NewFlagSet("simulate", flag.ExitOnError)
+	athletesPath := flags.String("athletes", "", "JSON file of athlete profiles (random field if empty)")
+	competitors := flags.Int("competitors", 30, "number of random athletes without an athletes file")
+	seed := flags.Uint64("seed", 0, "random seed of the simulation")
+	dnfRate := flags.Float64("dnf-rate", 0.03, "probability of a competitor not finishing")
+	lateStartRate := flags.Float64("late-start-rate", 0.02, "probability of a start outside of the start interval")
+	output := flags.String("output", "", "events destination file (stdout if empty)")
+	flags.Parse(args)
+
+	cfg, err := config.New()
+	if err != nil {
+		log.Fatalf("cannot get application config: %s", err)
+	}
+
+	athletes, err := readAthletes(*athletesPath, *competitors, *seed)
+	if err != nil {
+		log.Fatalf("cannot read athletes: %s", err)
+	}
+
+	events, err := simulate.Generate(cfg, simulate.Options{
+		Athletes:      athletes,
+		Seed:          *seed,
+		DNFRate:       *dnfRate,
+		LateStartRate: *lateStartRate,
+	})
+	if err != nil {
+		log.Fatalf("cannot simulate race: %s", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("cannot create events output: %s", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	w := bufio.NewWriter(out)
+	err = simulate.Write(w, events)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		log.Fatalf("cannot write events: %s", err)
+	}
+}
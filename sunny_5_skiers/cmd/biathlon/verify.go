@@ -0,0 +1,47 @@
+package main
+
+import (
+	"biathlon/internal/audit"
+	"biathlon/internal/results"
+	"flag"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func runVerify(args []string) {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	auditLog := flags.String("audit", "audit.jsonl", "hash-chained audit log file")
+	sheet := flags.String("results", "", "official results file whose audit chain head must match")
+	flags.Parse(args)
+
+	f, err := os.Open(*auditLog)
+	if err != nil {
+		log.Fatalf("cannot open audit log: %s", err)
+	}
+	defer f.Close()
+
+	head, n, err := audit.Verify(f)
+	if err != nil {
+		log.Fatalf("audit log verification failed: %s", err)
+	}
+
+	if *sheet != "" {
+		s, err := results.ReadSheet(*sheet)
+		if err != nil {
+			log.Fatalf("cannot read results: %s", err)
+		}
+
+		err = s.Verify([]byte(os.Getenv("BIATHLON_SIGNING_KEY")))
+		if err != nil {
+			log.Fatalf("results verification failed: %s", err)
+		}
+
+		if s.AuditHead != head {
+			log.Fatalf("results verification failed: audit chain head %s does not match %s", s.AuditHead, head)
+		}
+	}
+
+	fmt.Printf("audit log ok: %d records, head %s\n", n, head)
+}
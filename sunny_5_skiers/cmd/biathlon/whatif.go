@@ -0,0 +1,77 @@
+package main
+
+import (
+	"biathlon/config"
+	"biathlon/internal/results"
+	"biathlon/internal/whatif"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+)
+
+func runWhatIf(args []string) {
+	flags := flag.NewFlagSet("whatif", flag.ExitOnError)
+	events := flags.String("events", "events", "incoming events file")
+	asJSON := flags.Bool("json", false, "print the changes as JSON")
+	overrides := make(map[string]string)
+	flags.Func("set", "override a config option, as <key>=<value> (repeatable)", func(value string) error {
+		key, v, ok := strings.Cut(value, "=")
+		if !ok || key == "" {
+			return errIncorrectOverride
+		}
+		overrides[key] = v
+		return nil
+	})
+	var edits editList
+	edits.register(flags)
+	flags.Parse(args)
+
+	cfg, err := config.New()
+	if err != nil {
+		log.Fatalf("cannot get application config: %s", err)
+	}
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("cannot initialize logger: %s", err)
+	}
+
+	f, err := os.Open(*events)
+	if err != nil {
+		log.Fatalf("cannot open events file: %s", err)
+	}
+	defer f.Close()
+
+	race, err := whatif.Read(logger, cfg, f)
+	if err != nil {
+		log.Fatalf("cannot read events: %s", err)
+	}
+
+	changes, err := race.Compare(whatif.Scenario{Overrides: overrides, Edits: edits.list()})
+	if err != nil {
+		log.Fatalf("cannot evaluate scenario: %s", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(changes)
+	} else if len(changes) == 0 {
+		_, err = fmt.Println("no results changed")
+	} else {
+		err = results.WriteDiff(os.Stdout, changes)
+	}
+	if err != nil {
+		log.Fatalf("cannot write changes: %s", err)
+	}
+}
+
+var (
+	errIncorrectOverride = errors.New("override must be <key>=<value>")
+)
@@ -1,21 +1,70 @@
 package config
 
 import (
+	"biathlon/internal/util"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 )
 
+const (
+	FormatIndividual = "individual"
+	FormatSprint     = "sprint"
+	FormatPursuit    = "pursuit"
+	FormatMassStart  = "massStart"
+)
+
+const (
+	TieBreakerMisses = "misses"
+	TieBreakerBib    = "bib"
+)
+
+const (
+	StrictnessOff   = "off"
+	StrictnessWarn  = "warn"
+	StrictnessError = "error"
+)
+
 type Config struct {
+	// Format is the race format, individual by default.
+	Format      string `json:"format"`
 	Laps        int    `json:"laps"`
 	LapLen      int    `json:"lapLen"`
 	PenaltyLen  int    `json:"penaltyLen"`
 	FiringLines int    `json:"firingLines"`
 	Start       string `json:"start"`
 	StartDelta  string `json:"startDelta"`
+	// TieBreakers are applied in order to finishers with equal total time,
+	// supported values are "misses" and "bib". Ties left unresolved share a rank.
+	TieBreakers []string `json:"tieBreakers"`
+	// StartListStrictness controls how drawn start times that do not fit
+	// the start grid are reported: "off", "warn" (default) or "error".
+	StartListStrictness string `json:"startListStrictness"`
+	// CourseClosure is the clock time after which competitors still
+	// on the course are pulled out as over the time limit.
+	CourseClosure string   `json:"courseClosure"`
+	LapCutoffs    []Cutoff `json:"lapCutoffs"`
+	// ProtestWindow is the HH:MM:SS period after the last competitor
+	// left the course during which results can be protested.
+	ProtestWindow string `json:"protestWindow"`
+}
+
+// Cutoff limits the time to complete a lap either by the clock time or
+// by the time behind the leader of the lap. Empty values are not checked.
+type Cutoff struct {
+	Lap          int    `json:"lap"`
+	Clock        string `json:"clock"`
+	BehindLeader string `json:"behindLeader"`
 }
 
 func New() (*Config, error) {
-	configFile, err := os.ReadFile("config.json")
+	return Load("config.json")
+}
+
+// Load reads the config from the JSON file.
+func Load(path string) (*Config, error) {
+	configFile, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
@@ -26,5 +75,115 @@ func New() (*Config, error) {
 		return nil, err
 	}
 
+	err = config.Validate()
+	if err != nil {
+		return nil, err
+	}
 	return &config, nil
 }
+
+// Validate checks the options that would otherwise silently change the
+// race, like a misspelled format or tie-breaker or a time limit that
+// cannot be parsed and would not be enforced.
+func (c *Config) Validate() error {
+	switch c.Format {
+	case "", FormatIndividual, FormatSprint, FormatPursuit, FormatMassStart:
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownFormat, c.Format)
+	}
+
+	for _, name := range c.TieBreakers {
+		if name != TieBreakerMisses && name != TieBreakerBib {
+			return fmt.Errorf("%w: %s", ErrUnknownTieBreaker, name)
+		}
+	}
+
+	switch c.StartListStrictness {
+	case "", StrictnessOff, StrictnessWarn, StrictnessError:
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownStrictness, c.StartListStrictness)
+	}
+
+	if c.CourseClosure != "" {
+		if _, err := util.ConvertToTimestamp(c.CourseClosure); err != nil {
+			return fmt.Errorf("%w: course closure %q", ErrInvalidTimeLimit, c.CourseClosure)
+		}
+	}
+	for _, cut := range c.LapCutoffs {
+		if cut.Lap < 1 || cut.Lap > c.Laps {
+			return fmt.Errorf("%w: lap %d cut-off is outside the %d laps", ErrInvalidTimeLimit, cut.Lap, c.Laps)
+		}
+		if cut.Clock != "" {
+			if _, err := util.ConvertToTimestamp(cut.Clock); err != nil {
+				return fmt.Errorf("%w: lap %d cut-off clock %q", ErrInvalidTimeLimit, cut.Lap, cut.Clock)
+			}
+		}
+		if cut.BehindLeader != "" {
+			if _, err := util.ConvertToDuration(cut.BehindLeader); err != nil {
+				return fmt.Errorf("%w: lap %d cut-off behind leader %q", ErrInvalidTimeLimit, cut.Lap, cut.BehindLeader)
+			}
+		}
+	}
+	return nil
+}
+
+// Override returns a copy of the config with the JSON keys set to the
+// values, which are parsed as JSON or taken as strings otherwise.
+func (c *Config) Override(values map[string]string) (*Config, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range values {
+		if _, ok := fields[key]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownOption, key)
+		}
+
+		raw := json.RawMessage(value)
+		if !json.Valid(raw) {
+			raw, err = json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		fields[key] = raw
+	}
+
+	data, err = json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var res Config
+	err = json.Unmarshal(data, &res)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config option value: %w", err)
+	}
+
+	err = res.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// PullsLapped reports whether competitors who are about to be lapped by
+// the leader are removed from the course, as in pursuit and mass start.
+func (c *Config) PullsLapped() bool {
+	return c.Format == FormatPursuit || c.Format == FormatMassStart
+}
+
+var (
+	ErrUnknownOption     = errors.New("unknown config option")
+	ErrUnknownFormat     = errors.New("unknown race format")
+	ErrUnknownTieBreaker = errors.New("unknown tie-breaker")
+	ErrInvalidTimeLimit  = errors.New("invalid time limit")
+	ErrUnknownStrictness = errors.New("unknown start list strictness")
+)
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig(t *testing.T) {
+	t.Parallel()
+
+	load := func(t *testing.T, data string) (*Config, error) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+		return Load(path)
+	}
+
+	t.Run("tie-breakers test", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := load(t, `{"laps": 2, "tieBreakers": ["misses", "bib"]}`)
+		require.NoError(t, err)
+		require.Equal(t, []string{TieBreakerMisses, TieBreakerBib}, cfg.TieBreakers)
+
+		_, err = load(t, `{"laps": 2, "tieBreakers": ["mises"]}`)
+		require.ErrorIs(t, err, ErrUnknownTieBreaker)
+		require.ErrorContains(t, err, "mises")
+
+		_, err = cfg.Override(map[string]string{"tieBreakers": `["bib", "age"]`})
+		require.ErrorIs(t, err, ErrUnknownTieBreaker)
+	})
+
+	t.Run("format test", func(t *testing.T) {
+		t.Parallel()
+		for _, format := range []string{"", FormatIndividual, FormatSprint, FormatPursuit, FormatMassStart} {
+			_, err := load(t, `{"laps": 2, "format": "`+format+`"}`)
+			require.NoError(t, err, format)
+		}
+
+		_, err := load(t, `{"laps": 2, "format": "mass-start"}`)
+		require.ErrorIs(t, err, ErrUnknownFormat)
+		require.ErrorContains(t, err, "mass-start")
+	})
+
+	t.Run("start list strictness test", func(t *testing.T) {
+		t.Parallel()
+		for _, strictness := range []string{"", StrictnessOff, StrictnessWarn, StrictnessError} {
+			_, err := load(t, `{"laps": 2, "startListStrictness": "`+strictness+`"}`)
+			require.NoError(t, err, strictness)
+		}
+
+		_, err := load(t, `{"laps": 2, "startListStrictness": "strict"}`)
+		require.ErrorIs(t, err, ErrUnknownStrictness)
+		require.ErrorContains(t, err, "strict")
+	})
+
+	t.Run("time limits test", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := load(t, `{"laps": 2, "courseClosure": "11:00:00.000",
+			"lapCutoffs": [{"lap": 1, "clock": "10:30:00.000"}, {"lap": 2, "behindLeader": "00:10:00"}]}`)
+		require.NoError(t, err)
+		require.Len(t, cfg.LapCutoffs, 2)
+
+		for _, data := range []string{
+			`{"laps": 2, "courseClosure": "11:00"}`,
+			`{"laps": 2, "lapCutoffs": [{"lap": 1, "clock": "25:00:00.000"}]}`,
+			`{"laps": 2, "lapCutoffs": [{"lap": 1, "behindLeader": "10min"}]}`,
+			`{"laps": 2, "lapCutoffs": [{"lap": 3, "clock": "10:30:00.000"}]}`,
+		} {
+			_, err = load(t, data)
+			require.ErrorIs(t, err, ErrInvalidTimeLimit, data)
+		}
+
+		_, err = cfg.Override(map[string]string{"courseClosure": "noon"})
+		require.ErrorIs(t, err, ErrInvalidTimeLimit)
+	})
+}
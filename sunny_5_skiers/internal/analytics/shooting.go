@@ -0,0 +1,258 @@
+package analytics
+
+import (
+	"biathlon/internal/entity"
+	"biathlon/internal/util"
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// Accuracy is the number of hits out of the shots fired.
+type Accuracy struct {
+	Hits     int     `json:"hits"`
+	Shots    int     `json:"shots"`
+	Accuracy float64 `json:"accuracy"`
+}
+
+func (a *Accuracy) add(hits, shots int) {
+	a.Hits += hits
+	a.Shots += shots
+	if a.Shots > 0 {
+		a.Accuracy = float64(a.Hits) / float64(a.Shots)
+	}
+}
+
+func (a Accuracy) String() string {
+	return fmt.Sprintf("%d/%d %.1f%%", a.Hits, a.Shots, a.Accuracy*100)
+}
+
+// Bout is a completed range visit of a competitor. TimePerShot is the mean
+// interval between the recorded hits, empty with less than two hits.
+type Bout struct {
+	Bout       int `json:"bout"`
+	FiringLine int `json:"firingLine"`
+	Accuracy
+	RangeTime   string `json:"rangeTime"`
+	TimePerShot string `json:"timePerShot,omitempty"`
+}
+
+// FiringLine is the accuracy of a competitor on a firing line.
+type FiringLine struct {
+	FiringLine int `json:"firingLine"`
+	Accuracy
+}
+
+// Competitor is the shooting of a competitor over the completed range visits.
+type Competitor struct {
+	CompetitorID int64        `json:"competitor"`
+	Bouts        []Bout       `json:"bouts"`
+	FiringLines  []FiringLine `json:"firingLines"`
+	Accuracy
+	RangeTime   string `json:"rangeTime"`
+	TimePerShot string `json:"timePerShot,omitempty"`
+}
+
+// RangeTime is a place in a range time ranking.
+type RangeTime struct {
+	Rank         int    `json:"rank"`
+	CompetitorID int64  `json:"competitor"`
+	Time         string `json:"time"`
+}
+
+// Range is the field aggregate of the range visits with the same bout
+// number, with the competitors ranked by their range time.
+type Range struct {
+	Bout int `json:"bout"`
+	Accuracy
+	MeanTime string      `json:"meanTime"`
+	Times    []RangeTime `json:"times"`
+}
+
+// Report is the shooting analytics of a race. RangeTimes ranks the
+// competitors by their mean range time per bout.
+type Report struct {
+	Competitors []Competitor `json:"competitors"`
+	Ranges      []Range      `json:"ranges"`
+	RangeTimes  []RangeTime  `json:"rangeTimes"`
+}
+
+type timed struct {
+	id   int64
+	time time.Duration
+}
+
+// rank orders the times and gives equal times the same rank.
+func rank(times []timed) []RangeTime {
+	slices.SortStableFunc(times, func(a, b timed) int {
+		return cmp.Compare(a.time, b.time)
+	})
+
+	var res []RangeTime = make([]RangeTime, len(times))
+	for i, t := range times {
+		res[i] = RangeTime{Rank: i + 1, CompetitorID: t.id, Time: util.FormatDuration(t.time)}
+		if i > 0 && t.time == times[i-1].time {
+			res[i].Rank = res[i-1].Rank
+		}
+	}
+	return res
+}
+
+// shotTime returns the time between the first and the last recorded hit
+// and the number of intervals between them.
+func shotTime(r entity.RangeData) (time.Duration, int) {
+	if len(r.Hits) < 2 {
+		return 0, 0
+	}
+	return r.Hits[len(r.Hits)-1].Time.Sub(r.Hits[0].Time), len(r.Hits) - 1
+}
+
+func perShot(d time.Duration, intervals int) string {
+	if intervals == 0 {
+		return ""
+	}
+	return util.FormatDuration(d / time.Duration(intervals))
+}
+
+// Shooting analyses the completed range visits of the competitors in the
+// order of the standings.
+func Shooting(standings []entity.Standing) *Report {
+	res := &Report{Competitors: make([]Competitor, 0, len(standings))}
+	var ranges []Range
+	var bouts [][]timed
+	var means []timed
+
+	for _, s := range standings {
+		c := s.Competitor
+		competitor := Competitor{CompetitorID: c.ID}
+		var rangeTime, shots time.Duration
+		var intervals int
+
+		for _, r := range c.RangeData {
+			if r.Exit.IsZero() {
+				continue
+			}
+
+			n := len(competitor.Bouts)
+			d := r.Exit.Sub(r.Enter)
+			st, si := shotTime(r)
+			bout := Bout{
+				Bout:        n + 1,
+				FiringLine:  r.FiringLine,
+				RangeTime:   util.FormatDuration(d),
+				TimePerShot: perShot(st, si),
+			}
+			bout.add(len(r.Hits), entity.TargetsPerRange)
+			competitor.Bouts = append(competitor.Bouts, bout)
+			competitor.add(len(r.Hits), entity.TargetsPerRange)
+			rangeTime += d
+			shots += st
+			intervals += si
+
+			i, ok := slices.BinarySearchFunc(competitor.FiringLines, r.FiringLine, func(l FiringLine, line int) int {
+				return cmp.Compare(l.FiringLine, line)
+			})
+			if !ok {
+				competitor.FiringLines = slices.Insert(competitor.FiringLines, i, FiringLine{FiringLine: r.FiringLine})
+			}
+			competitor.FiringLines[i].add(len(r.Hits), entity.TargetsPerRange)
+
+			if n == len(ranges) {
+				ranges = append(ranges, Range{Bout: n + 1})
+				bouts = append(bouts, nil)
+			}
+			ranges[n].add(len(r.Hits), entity.TargetsPerRange)
+			bouts[n] = append(bouts[n], timed{id: c.ID, time: d})
+		}
+
+		if n := len(competitor.Bouts); n > 0 {
+			competitor.RangeTime = util.FormatDuration(rangeTime)
+			competitor.TimePerShot = perShot(shots, intervals)
+			means = append(means, timed{id: c.ID, time: rangeTime / time.Duration(n)})
+		}
+		res.Competitors = append(res.Competitors, competitor)
+	}
+
+	for i := range ranges {
+		var total time.Duration
+		for _, t := range bouts[i] {
+			total += t.time
+		}
+		ranges[i].MeanTime = util.FormatDuration(total / time.Duration(len(bouts[i])))
+		ranges[i].Times = rank(bouts[i])
+	}
+	res.Ranges = ranges
+	res.RangeTimes = rank(means)
+
+	return res
+}
+
+// WriteText prints the report per competitor, per range and the range
+// time ranking.
+func (r *Report) WriteText(w io.Writer) error {
+	for _, c := range r.Competitors {
+		if len(c.Bouts) == 0 {
+			continue
+		}
+
+		_, err := fmt.Fprintf(w, "competitor %d: %s, range %s%s\n", c.CompetitorID, c.Accuracy, c.RangeTime, withPerShot(c.TimePerShot))
+		if err != nil {
+			return err
+		}
+
+		for _, b := range c.Bouts {
+			_, err = fmt.Fprintf(w, "  bout %d line %d: %s, range %s%s\n", b.Bout, b.FiringLine, b.Accuracy, b.RangeTime, withPerShot(b.TimePerShot))
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, l := range c.FiringLines {
+			_, err = fmt.Fprintf(w, "  line %d: %s\n", l.FiringLine, l.Accuracy)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, rng := range r.Ranges {
+		_, err := fmt.Fprintf(w, "range %d: %s, mean range time %s\n", rng.Bout, rng.Accuracy, rng.MeanTime)
+		if err != nil {
+			return err
+		}
+
+		err = writeTimes(w, rng.Times)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(r.RangeTimes) == 0 {
+		return nil
+	}
+
+	_, err := fmt.Fprintln(w, "mean range time:")
+	if err != nil {
+		return err
+	}
+	return writeTimes(w, r.RangeTimes)
+}
+
+func withPerShot(s string) string {
+	if s == "" {
+		return ""
+	}
+	return fmt.Sprintf(", %s per shot", s)
+}
+
+func writeTimes(w io.Writer, times []RangeTime) error {
+	for _, t := range times {
+		_, err := fmt.Fprintf(w, "  %d %d %s\n", t.Rank, t.CompetitorID, t.Time)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
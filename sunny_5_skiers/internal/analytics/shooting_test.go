@@ -0,0 +1,94 @@
+package analytics
+
+import (
+	"biathlon/internal/entity"
+	"biathlon/internal/util"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShooting(t *testing.T) {
+	t.Parallel()
+	at := func(s string) time.Time {
+		ts, err := util.ConvertToTimestamp(s)
+		require.NoError(t, err)
+		return ts
+	}
+	hits := func(times ...string) []entity.Hit {
+		var res []entity.Hit = make([]entity.Hit, len(times))
+		for i, s := range times {
+			res[i] = entity.Hit{Target: i + 1, Time: at(s)}
+		}
+		return res
+	}
+
+	standings := []entity.Standing{
+		{Rank: 1, Competitor: &entity.Competitior{ID: 1, RangeData: []entity.RangeData{
+			{FiringLine: 1, Enter: at("10:10:00.000"), Exit: at("10:10:30.000"),
+				Hits: hits("10:10:10.000", "10:10:12.000", "10:10:14.000", "10:10:16.000", "10:10:18.000")},
+			{FiringLine: 2, Enter: at("10:20:00.000"), Exit: at("10:20:20.000"),
+				Hits: hits("10:20:10.000", "10:20:13.000")},
+		}}},
+		{Rank: 2, Competitor: &entity.Competitior{ID: 2, RangeData: []entity.RangeData{
+			{FiringLine: 1, Enter: at("10:11:00.000"), Exit: at("10:11:20.000"),
+				Hits: hits("10:11:10.000")},
+			{FiringLine: 1, Enter: at("10:21:00.000")},
+		}}},
+		{Competitor: &entity.Competitior{ID: 3}},
+	}
+
+	t.Run("competitor test", func(t *testing.T) {
+		t.Parallel()
+		report := Shooting(standings)
+		require.Len(t, report.Competitors, 3)
+
+		first := report.Competitors[0]
+		require.Equal(t, Accuracy{Hits: 7, Shots: 10, Accuracy: 0.7}, first.Accuracy)
+		require.Equal(t, "00:00:50.000", first.RangeTime)
+		require.Equal(t, "00:00:02.200", first.TimePerShot)
+		require.Equal(t, []Bout{
+			{Bout: 1, FiringLine: 1, Accuracy: Accuracy{Hits: 5, Shots: 5, Accuracy: 1}, RangeTime: "00:00:30.000", TimePerShot: "00:00:02.000"},
+			{Bout: 2, FiringLine: 2, Accuracy: Accuracy{Hits: 2, Shots: 5, Accuracy: 0.4}, RangeTime: "00:00:20.000", TimePerShot: "00:00:03.000"},
+		}, first.Bouts)
+		require.Equal(t, []FiringLine{
+			{FiringLine: 1, Accuracy: Accuracy{Hits: 5, Shots: 5, Accuracy: 1}},
+			{FiringLine: 2, Accuracy: Accuracy{Hits: 2, Shots: 5, Accuracy: 0.4}},
+		}, first.FiringLines)
+
+		second := report.Competitors[1]
+		require.Len(t, second.Bouts, 1, "open range visits are skipped")
+		require.Empty(t, second.TimePerShot)
+		require.Empty(t, report.Competitors[2].Bouts)
+	})
+
+	t.Run("range test", func(t *testing.T) {
+		t.Parallel()
+		report := Shooting(standings)
+		require.Len(t, report.Ranges, 2)
+
+		require.Equal(t, Accuracy{Hits: 6, Shots: 10, Accuracy: 0.6}, report.Ranges[0].Accuracy)
+		require.Equal(t, "00:00:25.000", report.Ranges[0].MeanTime)
+		require.Equal(t, []RangeTime{
+			{Rank: 1, CompetitorID: 2, Time: "00:00:20.000"},
+			{Rank: 2, CompetitorID: 1, Time: "00:00:30.000"},
+		}, report.Ranges[0].Times)
+
+		require.Equal(t, []RangeTime{
+			{Rank: 1, CompetitorID: 2, Time: "00:00:20.000"},
+			{Rank: 2, CompetitorID: 1, Time: "00:00:25.000"},
+		}, report.RangeTimes)
+	})
+
+	t.Run("text test", func(t *testing.T) {
+		t.Parallel()
+		var b strings.Builder
+		require.NoError(t, Shooting(standings).WriteText(&b))
+		require.Contains(t, b.String(), "competitor 1: 7/10 70.0%, range 00:00:50.000, 00:00:02.200 per shot\n")
+		require.Contains(t, b.String(), "  bout 2 line 2: 2/5 40.0%, range 00:00:20.000, 00:00:03.000 per shot\n")
+		require.Contains(t, b.String(), "range 1: 6/10 60.0%, mean range time 00:00:25.000\n")
+		require.NotContains(t, b.String(), "competitor 3")
+	})
+}
@@ -2,39 +2,296 @@ package app
 
 import (
 	"biathlon/config"
+	"biathlon/internal/audit"
+	"biathlon/internal/entity"
+	"biathlon/internal/eventlog"
 	"biathlon/internal/processor"
+	"biathlon/internal/results"
+	"biathlon/internal/tracing"
+	"biathlon/internal/util"
 	"biathlon/internal/validator"
 	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"go.uber.org/zap"
 )
 
-func Run(logger *zap.Logger, cfg *config.Config) error {
+type Options struct {
+	LogFormat eventlog.Format
+	LogOutput io.Writer
+	Output    io.Writer
+	Splits    bool
+	// Projections prints the projected results of competitors on the course.
+	Projections bool
+	// CourseTimes prints the rankings by course time.
+	CourseTimes bool
+	// AuditOutput receives the hash-chained audit log if not nil.
+	AuditOutput io.Writer
+	// Edits are applied to the event stream after it is processed.
+	Edits []validator.Edit
+	// ResultsOutput receives the results sheet as JSON if not nil.
+	ResultsOutput io.Writer
+	// TraceOutput receives a JSON span per phase of every incoming event
+	// if not nil.
+	TraceOutput io.Writer
+}
+
+type eventValidator interface {
+	Validate(rawData string) error
+	Line() int
+	Close()
+}
 
-	events, err := os.Open("events")
+func ingest(logger *zap.Logger, v eventValidator, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		err := v.Validate(scanner.Text())
+		if err != nil {
+			logger.Error("failed to validate event", zap.Int("line", v.Line()), zap.Error(err))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	v.Close()
+	return nil
+}
+
+func ingestFile(logger *zap.Logger, v eventValidator, path string) error {
+	events, err := os.Open(path)
 	if err != nil {
 		logger.Error("cannot open events file", zap.Error(err))
 		return err
 	}
 	defer events.Close()
 
-	validator := validator.New(logger, cfg, processor.New(cfg, logger))
-	reader := bufio.NewReader(events)
-	for {
-		line, err := reader.ReadString('\n')
+	return ingest(logger, v, events)
+}
+
+// newAuditChain subscribes an audit chain to the accepted events of the
+// processor. Nil output computes the chain head without writing records.
+func newAuditChain(proc processor.Processor, w io.Writer) *audit.Chain {
+	if w == nil {
+		w = io.Discard
+	}
+
+	chain := audit.NewChain(w)
+	proc.Subscribe(chain.Listen)
+	return chain
+}
+
+// raceClock returns the time of the latest event in the log.
+func raceClock(log []*entity.Event) time.Time {
+	var res time.Time
+	for i, e := range log {
+		if i == 0 || e.Timestamp.After(res) {
+			res = e.Timestamp
+		}
+	}
+	return res
+}
+
+func protestWindow(cfg *config.Config) (time.Duration, error) {
+	if cfg.ProtestWindow == "" {
+		return 0, nil
+	}
+	return util.ConvertToDuration(cfg.ProtestWindow)
+}
+
+func Run(logger *zap.Logger, cfg *config.Config, opts Options) error {
+	window, err := protestWindow(cfg)
+	if err != nil {
+		logger.Error("cannot parse protest window", zap.Error(err))
+		return err
+	}
+
+	proc := processor.New(cfg, logger)
+	chain := newAuditChain(proc, opts.AuditOutput)
+	validator := validator.New(logger, cfg, proc)
+	var provider *tracing.Provider
+	if opts.TraceOutput != nil {
+		provider, err = tracing.NewProvider(opts.TraceOutput)
 		if err != nil {
-			break
+			logger.Error("failed to create span exporter", zap.Error(err))
+			return err
 		}
+		validator.Trace(provider.Tracer(tracing.ScopeName))
+	}
+	err = ingestFile(logger, validator, "events")
+	if err != nil {
+		return err
+	}
 
-		err = validator.Validate(line)
+	if provider != nil {
+		err = errors.Join(provider.Shutdown(context.Background()), provider.Err())
 		if err != nil {
-			logger.Error("failed to validate event", zap.Error(err))
+			logger.Error("failed to export spans", zap.Error(err))
+			return err
 		}
 	}
 
-	validator.GetLog()
+	err = applyEdits(logger, validator, opts.Edits, opts.Output)
+	if err != nil {
+		return err
+	}
+
+	err = chain.Err()
+	if err != nil {
+		logger.Error("failed to write audit log", zap.Error(err))
+		return err
+	}
+
+	err = validator.GetLog(opts.LogOutput, opts.LogFormat)
+	if err != nil {
+		logger.Error("failed to write event log", zap.Error(err))
+		return err
+	}
 	validator.GetResult()
 
+	standings := proc.GetStandings()
+	clock := raceClock(proc.GetLog())
+	state := results.StateOf(standings, clock, window)
+	var deadline string
+	if state == results.StateUnofficial {
+		deadline = util.FormatTimestamp(results.ProtestDeadline(standings, window))
+		_, err = fmt.Fprintf(opts.Output, "results are %s, protests until %s\n", state, deadline)
+	} else {
+		_, err = fmt.Fprintf(opts.Output, "results are %s\n", state)
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.ResultsOutput != nil {
+		enc := json.NewEncoder(opts.ResultsOutput)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(&results.Sheet{
+			State:           state,
+			Clock:           util.FormatTimestamp(clock),
+			ProtestDeadline: deadline,
+			Rows:            results.NewRows(standings),
+			AuditHead:       chain.Head(),
+		})
+		if err != nil {
+			logger.Error("failed to write results", zap.Error(err))
+			return err
+		}
+	}
+
+	if opts.AuditOutput != nil {
+		_, err = fmt.Fprintf(opts.Output, "audit chain head %s (%d records)\n", chain.Head(), chain.Len())
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.Projections {
+		_, err = fmt.Fprintln(opts.Output, "projections:")
+		if err == nil {
+			err = validator.GetProjections(opts.Output)
+		}
+		if err != nil {
+			logger.Error("failed to write projections", zap.Error(err))
+			return err
+		}
+	}
+
+	if opts.CourseTimes {
+		err = validator.GetCourseTimes(opts.Output)
+		if err != nil {
+			logger.Error("failed to write course times", zap.Error(err))
+			return err
+		}
+	}
+
+	if opts.Splits {
+		err = validator.GetSplits(opts.Output)
+		if err != nil {
+			logger.Error("failed to write split times", zap.Error(err))
+			return err
+		}
+	}
+
 	return nil
 }
+
+// Standings processes the incoming events and returns the standings.
+func Standings(logger *zap.Logger, cfg *config.Config, r io.Reader) ([]entity.Standing, error) {
+	proc := processor.New(cfg, logger)
+	err := ingest(logger, validator.New(logger, cfg, proc), r)
+	if err != nil {
+		return nil, err
+	}
+	return proc.GetStandings(), nil
+}
+
+// Rows processes the incoming events and returns the result rows.
+func Rows(logger *zap.Logger, cfg *config.Config, r io.Reader) ([]results.Row, error) {
+	standings, err := Standings(logger, cfg, r)
+	if err != nil {
+		return nil, err
+	}
+	return results.NewRows(standings), nil
+}
+
+type FinalizeOptions struct {
+	Dir    string
+	Key    []byte
+	Clock  time.Time
+	Reason string
+	Force  bool
+	Output io.Writer
+	// AuditOutput receives the hash-chained audit log if not nil.
+	AuditOutput io.Writer
+}
+
+// Finalize processes the events and writes the results as a new official
+// version once the protest window is closed.
+func Finalize(logger *zap.Logger, cfg *config.Config, opts FinalizeOptions) error {
+	window, err := protestWindow(cfg)
+	if err != nil {
+		logger.Error("cannot parse protest window", zap.Error(err))
+		return err
+	}
+
+	proc := processor.New(cfg, logger)
+	chain := newAuditChain(proc, opts.AuditOutput)
+	err = ingestFile(logger, validator.New(logger, cfg, proc), "events")
+	if err != nil {
+		return err
+	}
+
+	err = chain.Err()
+	if err != nil {
+		logger.Error("failed to write audit log", zap.Error(err))
+		return err
+	}
+
+	standings := proc.GetStandings()
+	state := results.StateOf(standings, opts.Clock, window)
+	if state != results.StateProvisional && !opts.Force {
+		return fmt.Errorf("%w: results are %s", results.ErrNotFinal, state)
+	}
+
+	sheet, path, err := results.Finalize(opts.Dir, &results.Sheet{
+		Clock:           util.FormatTimestamp(opts.Clock),
+		ProtestDeadline: util.FormatTimestamp(results.ProtestDeadline(standings, window)),
+		Reason:          opts.Reason,
+		Rows:            results.NewRows(standings),
+		AuditHead:       chain.Head(),
+	}, opts.Key)
+	if err != nil {
+		logger.Error("failed to finalize results", zap.Error(err))
+		return err
+	}
+
+	_, err = fmt.Fprintf(opts.Output, "official results version %d: %s %s\n", sheet.Version, path, sheet.Hash)
+	return err
+}
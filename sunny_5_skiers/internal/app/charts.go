@@ -0,0 +1,70 @@
+package app
+
+import (
+	"biathlon/config"
+	"biathlon/internal/chart"
+	"biathlon/internal/processor"
+	"biathlon/internal/results"
+	"biathlon/internal/validator"
+	"io"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+)
+
+// Charts processes the incoming events and writes the gap to leader, lap
+// time and shooting charts as SVG files to the directory. It returns the
+// paths of the written files.
+func Charts(logger *zap.Logger, cfg *config.Config, r io.Reader, dir string) ([]string, error) {
+	proc := processor.New(cfg, logger)
+	err := ingest(logger, validator.New(logger, cfg, proc), r)
+	if err != nil {
+		return nil, err
+	}
+
+	var progress []chart.Progress
+	for _, point := range proc.TimingPoints() {
+		progress = append(progress, chart.Progress{Point: point, Rankings: proc.GetSplits(point)})
+	}
+	standings := proc.GetStandings()
+
+	charts := []struct {
+		name   string
+		render func(w io.Writer) error
+	}{
+		{"gaps.svg", func(w io.Writer) error { return chart.Gaps(w, progress) }},
+		{"laps.svg", func(w io.Writer) error { return chart.LapTimes(w, results.NewRows(standings)) }},
+		{"shooting.svg", func(w io.Writer) error { return chart.Shooting(w, standings) }},
+	}
+
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string = make([]string, len(charts))
+	for i, c := range charts {
+		res[i] = filepath.Join(dir, c.name)
+		err = writeChart(res[i], c.render)
+		if err != nil {
+			logger.Error("failed to write chart", zap.String("path", res[i]), zap.Error(err))
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func writeChart(path string, render func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = render(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
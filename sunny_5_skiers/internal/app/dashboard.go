@@ -0,0 +1,90 @@
+package app
+
+import (
+	"biathlon/config"
+	"biathlon/internal/dashboard"
+	"biathlon/internal/processor"
+	"biathlon/internal/validator"
+	"bufio"
+	"io"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type DashboardOptions struct {
+	Input  io.Reader
+	Output io.Writer
+	Width  int
+	Height int
+	// Refresh is the shortest interval between two redraws, zero redraws
+	// after every event.
+	Refresh time.Duration
+	// Delay is waited before every incoming event to replay recorded races.
+	Delay time.Duration
+}
+
+// Dashboard feeds the incoming events through the validator and processor
+// and redraws the dashboard as they are processed until the input ends.
+func Dashboard(logger *zap.Logger, cfg *config.Config, opts DashboardOptions) error {
+	proc := processor.New(cfg, logger)
+	board := dashboard.New(proc)
+	v := validator.New(logger, cfg, proc)
+
+	lines := make(chan string)
+	done := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(opts.Input)
+		for scanner.Scan() {
+			time.Sleep(opts.Delay)
+			lines <- scanner.Text()
+		}
+		done <- scanner.Err()
+		close(lines)
+	}()
+
+	render := func() error {
+		return board.Render(opts.Output, opts.Width, opts.Height)
+	}
+
+	var tick <-chan time.Time
+	if opts.Refresh > 0 {
+		ticker := time.NewTicker(opts.Refresh)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	dirty := false
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				err := render()
+				if err != nil {
+					return err
+				}
+				return <-done
+			}
+
+			err := v.Validate(line)
+			if err != nil {
+				board.Fail(v.Line(), err)
+			}
+			dirty = true
+			if tick != nil {
+				continue
+			}
+		case <-tick:
+			if !dirty {
+				continue
+			}
+		}
+
+		err := render()
+		if err != nil {
+			logger.Error("failed to render dashboard", zap.Error(err))
+			return err
+		}
+		dirty = false
+	}
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"biathlon/internal/entity"
+	"biathlon/internal/results"
+	"biathlon/internal/validator"
+	"fmt"
+	"io"
+
+	"go.uber.org/zap"
+)
+
+type eventEditor interface {
+	Edit(edit validator.Edit) ([]entity.ResultChange, error)
+}
+
+// applyEdits applies the edits in order and reports the changed results.
+func applyEdits(logger *zap.Logger, e eventEditor, edits []validator.Edit, w io.Writer) error {
+	for _, edit := range edits {
+		changes, err := e.Edit(edit)
+		if err != nil {
+			logger.Error("failed to edit event", zap.Stringer("edit", edit), zap.Error(err))
+			return fmt.Errorf("%s: %w", edit, err)
+		}
+
+		err = writeChanges(w, edit.String(), changes)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeChanges(w io.Writer, title string, changes []entity.ResultChange) error {
+	_, err := fmt.Fprintf(w, "%s, %d results changed\n", title, len(changes))
+	if err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		_, err = fmt.Fprintf(w, "  competitor(%d): %s -> %s\n", c.CompetitorID, results.OrNone(c.Before), results.OrNone(c.After))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
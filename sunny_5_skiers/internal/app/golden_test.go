@@ -0,0 +1,70 @@
+package app
+
+import (
+	"biathlon/config"
+	"biathlon/internal/eventlog"
+	"biathlon/internal/processor"
+	"biathlon/internal/validator"
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var update = flag.Bool("update", false, "regenerate the expected outputs of the scenarios")
+
+// TestScenarios runs every testdata/scenarios directory with config.json
+// and events files through the validator and processor and compares the
+// event log, results, projections of the competitors still on the course
+// and course time rankings with the expected log, results, projections
+// and course-times files.
+func TestScenarios(t *testing.T) {
+	t.Parallel()
+	dirs, err := filepath.Glob(filepath.Join("testdata", "scenarios", "*"))
+	require.NoError(t, err)
+	require.NotEmpty(t, dirs)
+
+	for _, dir := range dirs {
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			t.Parallel()
+			cfg, err := config.Load(filepath.Join(dir, "config.json"))
+			require.NoError(t, err)
+
+			events, err := os.Open(filepath.Join(dir, "events"))
+			require.NoError(t, err)
+			defer events.Close()
+
+			logger := zap.NewNop()
+			proc := processor.New(cfg, logger)
+			v := validator.New(logger, cfg, proc)
+			require.NoError(t, ingest(logger, v, events))
+
+			var log bytes.Buffer
+			require.NoError(t, v.GetLog(&log, eventlog.FormatText))
+			golden(t, filepath.Join(dir, "log"), log.String())
+			golden(t, filepath.Join(dir, "results"), strings.Join(proc.GetResult(), "\n")+"\n")
+
+			var projections, courseTimes bytes.Buffer
+			require.NoError(t, v.GetProjections(&projections))
+			golden(t, filepath.Join(dir, "projections"), projections.String())
+			require.NoError(t, v.GetCourseTimes(&courseTimes))
+			golden(t, filepath.Join(dir, "course-times"), courseTimes.String())
+		})
+	}
+}
+
+func golden(t *testing.T, path, actual string) {
+	if *update {
+		require.NoError(t, os.WriteFile(path, []byte(actual), 0o644))
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, string(expected), actual)
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"biathlon/config"
+	"biathlon/internal/metrics"
+	"biathlon/internal/processor"
+	"biathlon/internal/validator"
+	"bufio"
+	"io"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type ServeOptions struct {
+	Input io.Reader
+	// Addr is the listen address of the metrics endpoint.
+	Addr string
+}
+
+// Serve exposes the metrics at /metrics while the incoming events are
+// processed and keeps serving them after the input ends until the server
+// fails. A server failure stops the processing at once.
+func Serve(logger *zap.Logger, cfg *config.Config, opts ServeOptions) error {
+	proc := processor.New(cfg, logger)
+	m := metrics.New(proc, time.Now)
+	v := validator.New(logger, cfg, proc)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	server := &http.Server{Addr: opts.Addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.ListenAndServe()
+	}()
+
+	lines := make(chan string)
+	scanned := make(chan error, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(opts.Input)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-stop:
+				return
+			}
+		}
+		scanned <- scanner.Err()
+	}()
+
+	for {
+		select {
+		case err := <-done:
+			logger.Error("metrics server failed", zap.Error(err))
+			return err
+		case line, ok := <-lines:
+			if !ok {
+				if err := <-scanned; err != nil {
+					server.Close()
+					return err
+				}
+				logger.Info("all events processed, serving metrics", zap.String("addr", opts.Addr))
+				return <-done
+			}
+
+			err := m.Validate(v, line)
+			if err != nil {
+				logger.Error("failed to validate event", zap.Int("line", v.Line()), zap.Error(err))
+			}
+		}
+	}
+}
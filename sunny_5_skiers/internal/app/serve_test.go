@@ -0,0 +1,39 @@
+package app
+
+import (
+	"biathlon/config"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestServe(t *testing.T) {
+	t.Parallel()
+
+	t.Run("server failure test", func(t *testing.T) {
+		t.Parallel()
+		taken, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer taken.Close()
+
+		// The input never ends, the server error has to stop the serve.
+		input, w := io.Pipe()
+		defer w.Close()
+
+		done := make(chan error, 1)
+		go func() {
+			done <- Serve(zap.NewNop(), &config.Config{}, ServeOptions{Input: input, Addr: taken.Addr().String()})
+		}()
+
+		select {
+		case err := <-done:
+			require.Error(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("serve did not return after the server failed")
+		}
+	})
+}
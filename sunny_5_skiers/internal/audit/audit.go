@@ -0,0 +1,149 @@
+package audit
+
+import (
+	"biathlon/internal/entity"
+	"biathlon/internal/eventlog"
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Record is a line of the audit log. Hash covers the record content and
+// the hash of the previous record, so changing or removing any record
+// breaks the chain from that point on.
+type Record struct {
+	Seq int `json:"seq"`
+	eventlog.Record
+	// Removed marks a log entry a recomputation of the race took out of
+	// the log.
+	Removed bool   `json:"removed,omitempty"`
+	Prev    string `json:"prev"`
+	Hash    string `json:"hash"`
+}
+
+func (r *Record) digest() (string, error) {
+	content := *r
+	content.Hash = ""
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+type Chain struct {
+	enc  *json.Encoder
+	seq  int
+	head string
+	err  error
+}
+
+func NewChain(w io.Writer) *Chain {
+	return &Chain{enc: json.NewEncoder(w)}
+}
+
+func (c *Chain) Append(e *entity.Event) error {
+	return c.append(Record{Record: eventlog.NewRecord(e)})
+}
+
+func (c *Chain) append(record Record) error {
+	record.Seq = c.seq + 1
+	record.Prev = c.head
+	hash, err := record.digest()
+	if err != nil {
+		return err
+	}
+	record.Hash = hash
+
+	err = c.enc.Encode(record)
+	if err != nil {
+		return err
+	}
+
+	c.seq = record.Seq
+	c.head = hash
+	return nil
+}
+
+// Listen appends the log entries caused by a processed event, it matches
+// the processor listener signature. The entries removed by a
+// recomputation follow its entry. The first write error is kept in Err.
+func (c *Chain) Listen(_ *entity.Event, logged []*entity.Event, _ error) {
+	for _, e := range logged {
+		if c.err != nil {
+			return
+		}
+		c.err = c.Append(e)
+		if e.Recompute == nil {
+			continue
+		}
+
+		for _, removed := range e.Recompute.Removed {
+			if c.err != nil {
+				return
+			}
+			c.err = c.append(Record{Record: eventlog.NewRecord(removed), Removed: true})
+		}
+	}
+}
+
+func (c *Chain) Err() error {
+	return c.err
+}
+
+// Head returns the hash of the last appended record.
+func (c *Chain) Head() string {
+	return c.head
+}
+
+func (c *Chain) Len() int {
+	return c.seq
+}
+
+// Verify checks the whole chain and returns its head and length.
+func Verify(r io.Reader) (string, int, error) {
+	var head string
+	var seq int
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record Record
+		err := json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			return head, seq, fmt.Errorf("record %d: %w", seq+1, err)
+		}
+
+		if record.Seq != seq+1 {
+			return head, seq, fmt.Errorf("record %d: %w: got sequence number %d", seq+1, ErrBrokenChain, record.Seq)
+		}
+
+		if record.Prev != head {
+			return head, seq, fmt.Errorf("record %d: %w: previous hash mismatch", record.Seq, ErrBrokenChain)
+		}
+
+		hash, err := record.digest()
+		if err != nil {
+			return head, seq, err
+		}
+
+		if hash != record.Hash {
+			return head, seq, fmt.Errorf("record %d: %w: hash mismatch", record.Seq, ErrBrokenChain)
+		}
+
+		head, seq = hash, record.Seq
+	}
+
+	return head, seq, scanner.Err()
+}
+
+var (
+	ErrBrokenChain = errors.New("audit chain is broken")
+)
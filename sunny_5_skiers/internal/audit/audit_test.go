@@ -0,0 +1,139 @@
+package audit
+
+import (
+	"biathlon/config"
+	"biathlon/internal/entity"
+	"biathlon/internal/eventlog"
+	"biathlon/internal/processor"
+	"biathlon/internal/util"
+	"biathlon/internal/validator"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+	ts, err := util.ConvertToTimestamp("10:00:00.000")
+	require.NoError(t, err)
+
+	write := func(t *testing.T) (*Chain, string) {
+		var buf bytes.Buffer
+		chain := NewChain(&buf)
+		chain.Listen(nil, []*entity.Event{
+			{Timestamp: ts, Kind: 1, CompetitorID: 1, Comment: "The competitor(1) registered", Line: 1},
+			{Timestamp: ts, Kind: 4, CompetitorID: 1, Comment: "The competitor(1) has started", Line: 2},
+		}, nil)
+		require.NoError(t, chain.Append(entity.FinishEvent(1, ts)))
+		require.NoError(t, chain.Err())
+		return chain, buf.String()
+	}
+
+	t.Run("verify test", func(t *testing.T) {
+		t.Parallel()
+		chain, log := write(t)
+
+		head, n, err := Verify(strings.NewReader(log))
+		require.NoError(t, err)
+		require.Equal(t, 3, n)
+		require.Equal(t, chain.Head(), head)
+	})
+
+	t.Run("edit test", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		chain := NewChain(&buf)
+		old := &entity.Event{Timestamp: ts, Kind: 4, CompetitorID: 1, Line: 2}
+		edit := entity.EditEvent(2, &entity.EventEdit{Old: old, New: &entity.Event{Timestamp: ts, Kind: 4, CompetitorID: 2}, Reason: "wrong bib"})
+		chain.Listen(edit, []*entity.Event{edit}, nil)
+		require.NoError(t, chain.Err())
+
+		var record Record
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		require.Equal(t, 2, record.Line)
+		require.Equal(t, "wrong bib", record.Params)
+		require.Equal(t, "[10:00:00.000] 4 1", record.Old)
+		require.Equal(t, "[10:00:00.000] 4 2", record.New)
+
+		_, n, err := Verify(strings.NewReader(strings.Replace(buf.String(), "4 2", "4 3", 1)))
+		require.ErrorIs(t, err, ErrBrokenChain)
+		require.Equal(t, 0, n)
+	})
+
+	t.Run("recompute test", func(t *testing.T) {
+		t.Parallel()
+		cfg := &config.Config{Laps: 1, LapLen: 3000, Start: "10:00:00.000", StartDelta: "00:01:00"}
+		logger := zap.NewNop()
+		proc := processor.New(cfg, logger)
+		var buf bytes.Buffer
+		chain := NewChain(&buf)
+		proc.Subscribe(chain.Listen)
+		v := validator.New(logger, cfg, proc)
+		for _, line := range []string{
+			"[09:30:00.000] 1 1",
+			"[09:30:00.000] 1 2",
+			"[09:40:00.000] 2 1 10:00:00.000",
+			"[09:40:00.000] 2 2 10:01:00.000",
+			"[10:00:00.400] 4 1",
+			"[10:03:30.000] 4 2",
+			"[10:12:00.000] 10 1",
+			"[10:14:00.000] 10 2",
+			"[10:21:00.000] 13 2 REINSTATE start gate malfunction",
+			"[10:22:00.000] 14 1 7 time=10:11:00.000 photo finish",
+		} {
+			// Rejected events stay out of the log until a recomputation.
+			_ = v.Validate(line)
+		}
+		require.NoError(t, chain.Err())
+
+		// Replaying the chain without the edit and recomputation entries
+		// gives the log of the processor.
+		var replayed []eventlog.Record
+		var recomputed int
+		scanner := bufio.NewScanner(&buf)
+		for scanner.Scan() {
+			var record Record
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+			switch {
+			case record.Kind == entity.KindRecompute:
+				recomputed++
+			case record.Removed:
+				i := slices.Index(replayed, record.Record)
+				require.GreaterOrEqual(t, i, 0, record.Message)
+				replayed = slices.Delete(replayed, i, i+1)
+			default:
+				replayed = append(replayed, record.Record)
+			}
+		}
+		require.Equal(t, 2, recomputed)
+
+		var log []eventlog.Record
+		for _, e := range proc.GetLog() {
+			log = append(log, eventlog.NewRecord(e))
+		}
+		require.ElementsMatch(t, log, replayed)
+		require.True(t, slices.ContainsFunc(log, func(r eventlog.Record) bool {
+			return r.Kind == 33 && r.CompetitorID == 2
+		}))
+	})
+
+	t.Run("tamper test", func(t *testing.T) {
+		t.Parallel()
+		_, log := write(t)
+
+		_, n, err := Verify(strings.NewReader(strings.Replace(log, `"competitor":1`, `"competitor":2`, 1)))
+		require.ErrorIs(t, err, ErrBrokenChain)
+		require.Equal(t, 0, n)
+
+		lines := strings.SplitAfter(log, "\n")
+		_, n, err = Verify(strings.NewReader(lines[0] + lines[2]))
+		require.ErrorIs(t, err, ErrBrokenChain)
+		require.Equal(t, 1, n)
+	})
+}
@@ -0,0 +1,92 @@
+// Package chart renders race charts as standalone SVG documents.
+package chart
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	fontSize = 12
+	margin   = 40
+)
+
+// palette holds the line and bar colors, used in turn.
+var palette = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd",
+	"#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf",
+}
+
+func color(i int) string {
+	return palette[i%len(palette)]
+}
+
+// svg writes the elements of a document and keeps the first write error.
+type svg struct {
+	w   io.Writer
+	err error
+}
+
+func newSVG(w io.Writer, width, height int, title string) *svg {
+	s := &svg{w: w}
+	s.printf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="%d">`+"\n",
+		width, height, width, height, fontSize)
+	s.printf(`<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+	s.text(float64(width)/2, margin/2, "middle", title)
+	return s
+}
+
+func (s *svg) printf(format string, args ...any) {
+	if s.err != nil {
+		return
+	}
+	_, s.err = fmt.Fprintf(s.w, format, args...)
+}
+
+func (s *svg) text(x, y float64, anchor, text string) {
+	s.printf(`<text x="%.1f" y="%.1f" text-anchor="%s">%s</text>`+"\n", x, y, anchor, escape(text))
+}
+
+func (s *svg) line(x1, y1, x2, y2 float64, stroke string) {
+	s.printf(`<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s"/>`+"\n", x1, y1, x2, y2, stroke)
+}
+
+// rect draws a rectangle with the title shown on hover unless it is empty.
+func (s *svg) rect(x, y, width, height float64, fill, title string) {
+	if title == "" {
+		s.printf(`<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`+"\n", x, y, width, height, fill)
+		return
+	}
+	s.printf(`<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s</title></rect>`+"\n",
+		x, y, width, height, fill, escape(title))
+}
+
+func (s *svg) circle(x, y, r float64, fill, stroke, title string) {
+	s.printf(`<circle cx="%.1f" cy="%.1f" r="%.1f" fill="%s" stroke="%s"><title>%s</title></circle>`+"\n",
+		x, y, r, fill, stroke, escape(title))
+}
+
+func (s *svg) polyline(points [][2]float64, stroke, title string) {
+	var b strings.Builder
+	for i, p := range points {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%.1f,%.1f", p[0], p[1])
+	}
+	s.printf(`<polyline points="%s" fill="none" stroke="%s" stroke-width="2"><title>%s</title></polyline>`+"\n",
+		b.String(), stroke, escape(title))
+}
+
+func (s *svg) close() error {
+	s.printf("</svg>\n")
+	return s.err
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,95 @@
+package chart
+
+import (
+	"biathlon/internal/entity"
+	"biathlon/internal/results"
+	"biathlon/internal/util"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// elements parses the document and counts its elements by name.
+func elements(t *testing.T, doc string) map[string]int {
+	res := make(map[string]int)
+	dec := xml.NewDecoder(strings.NewReader(doc))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return res
+		}
+		require.NoError(t, err)
+		if start, ok := tok.(xml.StartElement); ok {
+			res[start.Name.Local]++
+		}
+	}
+}
+
+func TestChart(t *testing.T) {
+	t.Parallel()
+	at := func(s string) time.Time {
+		ts, err := util.ConvertToTimestamp(s)
+		require.NoError(t, err)
+		return ts
+	}
+
+	t.Run("gaps test", func(t *testing.T) {
+		t.Parallel()
+		var b strings.Builder
+		require.NoError(t, Gaps(&b, []Progress{
+			{Point: entity.TimingPoint{Kind: entity.PointLapEnd, Index: 1}, Rankings: []entity.SplitRanking{
+				{Position: 1, CompetitorID: 2},
+				{Position: 2, CompetitorID: 1, Gap: 10 * time.Second},
+			}},
+			{Point: entity.TimingPoint{Kind: entity.PointLapEnd, Index: 2}, Rankings: []entity.SplitRanking{
+				{Position: 1, CompetitorID: 1},
+			}},
+		}))
+
+		count := elements(t, b.String())
+		require.Equal(t, 2, count["polyline"])
+		require.Equal(t, 3, count["circle"])
+		require.Contains(t, b.String(), ">lap 2</text>")
+		require.Contains(t, b.String(), ">+00:10.0</text>")
+	})
+
+	t.Run("laps test", func(t *testing.T) {
+		t.Parallel()
+		var b strings.Builder
+		require.NoError(t, LapTimes(&b, []results.Row{
+			{CompetitorID: 1, Laps: []results.Lap{{Time: "00:10:00.000"}, {Time: "00:11:00.000"}}},
+			{CompetitorID: 2, Laps: []results.Lap{{Time: "00:12:00.000"}, {}}},
+			{CompetitorID: 3, Laps: []results.Lap{{}}},
+		}))
+
+		require.Equal(t, 1+3+2, elements(t, b.String())["rect"], "background, bars and legend")
+		require.Contains(t, b.String(), "<title>competitor 2 lap 1 00:12:00.000</title>")
+		require.NotContains(t, b.String(), "competitor 3")
+	})
+
+	t.Run("shooting test", func(t *testing.T) {
+		t.Parallel()
+		var b strings.Builder
+		require.NoError(t, Shooting(&b, []entity.Standing{
+			{Competitor: &entity.Competitior{ID: 1, RangeData: []entity.RangeData{
+				{FiringLine: 1, Enter: at("10:10:00.000"), Exit: at("10:10:30.000"), Hits: []entity.Hit{{Target: 2}, {Target: 5}}},
+				{FiringLine: 2, Enter: at("10:20:00.000")},
+			}}},
+			{Competitor: &entity.Competitior{ID: 2}},
+		}))
+
+		require.Equal(t, entity.TargetsPerRange, elements(t, b.String())["circle"])
+		require.Equal(t, 2, strings.Count(b.String(), " hit</title>"))
+		require.Contains(t, b.String(), "<title>competitor 1 bout 1 line 1 target 1 miss</title>")
+		require.NotContains(t, b.String(), "bout 2")
+	})
+
+	t.Run("escape test", func(t *testing.T) {
+		t.Parallel()
+		require.Equal(t, "a &lt;b&gt; &amp; c", escape("a <b> & c"))
+	})
+}
@@ -0,0 +1,89 @@
+package chart
+
+import (
+	"biathlon/internal/entity"
+	"biathlon/internal/util"
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// Progress is the virtual standings at a timing point.
+type Progress struct {
+	Point    entity.TimingPoint
+	Rankings []entity.SplitRanking
+}
+
+// Gaps draws the time behind the leader of every competitor at each
+// timing point, the leader at the top.
+func Gaps(w io.Writer, progress []Progress) error {
+	const (
+		width  = 800
+		height = 480
+		left   = 80
+		right  = 50
+		top    = margin
+		bottom = 90
+	)
+	plotWidth := float64(width - left - right)
+	plotHeight := float64(height - top - bottom)
+
+	var maxGap time.Duration
+	lines := make(map[int64][][2]float64)
+	var ids []int64
+	x := func(i int) float64 {
+		if len(progress) < 2 {
+			return left + plotWidth/2
+		}
+		return left + plotWidth*float64(i)/float64(len(progress)-1)
+	}
+	for _, p := range progress {
+		for _, r := range p.Rankings {
+			maxGap = max(maxGap, r.Gap)
+		}
+	}
+	if maxGap == 0 {
+		maxGap = time.Second
+	}
+	y := func(gap time.Duration) float64 {
+		return top + plotHeight*float64(gap)/float64(maxGap)
+	}
+
+	for i, p := range progress {
+		for _, r := range p.Rankings {
+			if _, ok := lines[r.CompetitorID]; !ok {
+				ids = append(ids, r.CompetitorID)
+			}
+			lines[r.CompetitorID] = append(lines[r.CompetitorID], [2]float64{x(i), y(r.Gap)})
+		}
+	}
+	slices.SortFunc(ids, cmp.Compare[int64])
+
+	s := newSVG(w, width, height, "Gap to leader")
+	for k := 0; k <= 4; k++ {
+		gap := maxGap * time.Duration(k) / 4
+		s.line(left, y(gap), left+plotWidth, y(gap), "#dddddd")
+		s.text(left-6, y(gap)+fontSize/3, "end", util.FormatGap(gap))
+	}
+
+	for i, p := range progress {
+		s.line(x(i), top, x(i), top+plotHeight, "#eeeeee")
+		s.printf(`<text x="%.1f" y="%.1f" text-anchor="end" transform="rotate(-45 %.1f %.1f)">%s</text>`+"\n",
+			x(i), top+plotHeight+16, x(i), top+plotHeight+16, escape(p.Point.String()))
+	}
+
+	for i, id := range ids {
+		points := lines[id]
+		title := fmt.Sprintf("competitor %d", id)
+		s.polyline(points, color(i), title)
+		for _, p := range points {
+			s.circle(p[0], p[1], 3, color(i), color(i), title)
+		}
+		last := points[len(points)-1]
+		s.text(last[0]+8, last[1]+fontSize/3, "start", fmt.Sprint(id))
+	}
+
+	return s.close()
+}
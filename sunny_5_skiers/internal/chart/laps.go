@@ -0,0 +1,88 @@
+package chart
+
+import (
+	"biathlon/internal/results"
+	"biathlon/internal/util"
+	"fmt"
+	"io"
+	"time"
+)
+
+// LapTimes draws a group of bars with the lap times of every competitor
+// who completed a lap.
+func LapTimes(w io.Writer, rows []results.Row) error {
+	const (
+		height = 400
+		left   = 80
+		top    = margin
+		bottom = 40
+		group  = 60
+		legend = 60
+	)
+
+	type competitor struct {
+		id   int64
+		laps []time.Duration
+	}
+	var competitors []competitor
+	var maxTime time.Duration
+	laps := 0
+	for _, r := range rows {
+		c := competitor{id: r.CompetitorID, laps: make([]time.Duration, len(r.Laps))}
+		completed := false
+		for i, l := range r.Laps {
+			if l.Time == "" {
+				continue
+			}
+			d, err := util.ParseDuration(l.Time)
+			if err != nil {
+				return err
+			}
+			c.laps[i] = d
+			maxTime = max(maxTime, d)
+			completed = true
+		}
+		if completed {
+			competitors = append(competitors, c)
+			laps = max(laps, len(r.Laps))
+		}
+	}
+	if maxTime == 0 {
+		maxTime = time.Second
+	}
+
+	plotWidth := max(len(competitors), 1) * group
+	width := left + plotWidth + legend + margin
+	plotHeight := float64(height - top - bottom)
+	y := func(d time.Duration) float64 {
+		return top + plotHeight - plotHeight*float64(d)/float64(maxTime)
+	}
+
+	s := newSVG(w, width, height, "Lap times")
+	for k := 0; k <= 4; k++ {
+		d := maxTime * time.Duration(k) / 4
+		s.line(left, y(d), float64(left+plotWidth), y(d), "#dddddd")
+		s.text(left-6, y(d)+fontSize/3, "end", util.FormatDuration(d))
+	}
+
+	barWidth := float64(group) * 0.8 / float64(max(laps, 1))
+	for i, c := range competitors {
+		x := float64(left + i*group + group/10)
+		for lap, d := range c.laps {
+			if d == 0 {
+				continue
+			}
+			title := fmt.Sprintf("competitor %d lap %d %s", c.id, lap+1, util.FormatDuration(d))
+			s.rect(x+float64(lap)*barWidth, y(d), barWidth, y(0)-y(d), color(lap), title)
+		}
+		s.text(float64(left+i*group+group/2), y(0)+fontSize+4, "middle", fmt.Sprint(c.id))
+	}
+
+	for lap := range laps {
+		x := float64(left + plotWidth + 10)
+		s.rect(x, float64(top+lap*16), 10, 10, color(lap), "")
+		s.text(x+14, float64(top+lap*16+10), "start", fmt.Sprintf("lap %d", lap+1))
+	}
+
+	return s.close()
+}
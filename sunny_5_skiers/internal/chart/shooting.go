@@ -0,0 +1,71 @@
+package chart
+
+import (
+	"biathlon/internal/entity"
+	"fmt"
+	"io"
+)
+
+// Shooting draws a matrix of the targets hit and missed in every completed
+// range visit of the competitors in the order of the standings.
+func Shooting(w io.Writer, standings []entity.Standing) error {
+	const (
+		cell = 16
+		gap  = 16
+		left = 80
+		top  = margin + 24
+	)
+
+	bouts := 0
+	var shooters []*entity.Competitior
+	for _, s := range standings {
+		n := 0
+		for _, r := range s.Competitor.RangeData {
+			if !r.Exit.IsZero() {
+				n++
+			}
+		}
+		if n > 0 {
+			shooters = append(shooters, s.Competitor)
+			bouts = max(bouts, n)
+		}
+	}
+
+	boutWidth := entity.TargetsPerRange*cell + gap
+	width := left + max(bouts, 1)*boutWidth + margin
+	height := top + max(len(shooters), 1)*cell + margin
+
+	s := newSVG(w, width, height, "Shooting")
+	for b := range bouts {
+		s.text(float64(left+b*boutWidth+entity.TargetsPerRange*cell/2), top-8, "middle", fmt.Sprintf("bout %d", b+1))
+	}
+
+	for row, c := range shooters {
+		y := float64(top + row*cell + cell/2)
+		s.text(left-8, y+fontSize/3, "end", fmt.Sprint(c.ID))
+
+		b := 0
+		for _, r := range c.RangeData {
+			if r.Exit.IsZero() {
+				continue
+			}
+
+			hit := make(map[int]bool, len(r.Hits))
+			for _, h := range r.Hits {
+				hit[h.Target] = true
+			}
+			for target := 1; target <= entity.TargetsPerRange; target++ {
+				x := float64(left + b*boutWidth + (target-1)*cell + cell/2)
+				title := fmt.Sprintf("competitor %d bout %d line %d target %d", c.ID, b+1, r.FiringLine, target)
+				if hit[target] {
+					s.circle(x, y, cell/2-2, "#2ca02c", "#2ca02c", title+" hit")
+				} else {
+					s.circle(x, y, cell/2-2, "white", "#d62728", title+" miss")
+				}
+			}
+			b++
+		}
+	}
+
+	return s.close()
+}
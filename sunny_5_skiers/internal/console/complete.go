@@ -0,0 +1,151 @@
+package console
+
+import (
+	"biathlon/internal/entity"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var commands = []string{"cancel", "help", "log", "ok", "quit", "standings"}
+
+var kindNames = map[int64]string{
+	1:  "registered",
+	2:  "start time drawn",
+	3:  "on the start line",
+	4:  "started",
+	5:  "entered the firing range",
+	6:  "target hit",
+	7:  "left the firing range",
+	8:  "entered the penalty loop",
+	9:  "left the penalty loop",
+	10: "ended the lap",
+	11: "can't continue",
+	12: "jury time penalty",
+	13: "jury status change",
+	14: "jury event correction",
+}
+
+// Complete returns the completions of the last word of the line: the
+// commands, event kinds, registered competitors or the parameters the
+// event kind takes.
+func (c *Console) Complete(line string) []string {
+	fields := strings.Fields(line)
+	partial := ""
+	if len(fields) > 0 && !strings.HasSuffix(line, " ") {
+		partial = fields[len(fields)-1]
+		fields = fields[:len(fields)-1]
+	}
+
+	if len(fields) == 0 && strings.HasPrefix(partial, "[") {
+		return []string{fmt.Sprintf("[%s]", c.clock())}
+	}
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "[") {
+		fields = fields[1:]
+	} else if len(fields) == 0 {
+		var res []string
+		for _, command := range commands {
+			if partial != "" && strings.HasPrefix(command, partial) {
+				res = append(res, command)
+			}
+		}
+		if len(res) > 0 {
+			return res
+		}
+	}
+
+	switch len(fields) {
+	case 0:
+		return c.completeKind(partial)
+	case 1:
+		return c.completeCompetitor(fields[0], partial)
+	case 2:
+		return c.completeParam(fields[0], fields[1], partial)
+	}
+	return nil
+}
+
+func (c *Console) completeKind(partial string) []string {
+	var res []string
+	for kind := int64(1); kind <= int64(len(kindNames)); kind++ {
+		s := strconv.FormatInt(kind, 10)
+		if strings.HasPrefix(s, partial) {
+			res = append(res, fmt.Sprintf("%s (%s)", s, kindNames[kind]))
+		}
+	}
+	return res
+}
+
+func (c *Console) completeCompetitor(kind, partial string) []string {
+	var ids []int64
+	for _, s := range c.proc.GetStandings() {
+		ids = append(ids, s.Competitor.ID)
+	}
+	slices.Sort(ids)
+
+	if kind == "1" {
+		next := int64(1)
+		if len(ids) > 0 {
+			next = ids[len(ids)-1] + 1
+		}
+		return []string{fmt.Sprintf("%d (next free)", next)}
+	}
+
+	k, _ := strconv.ParseInt(kind, 10, 64)
+	var res []string
+	for _, id := range ids {
+		s := strconv.FormatInt(id, 10)
+		if !strings.HasPrefix(s, partial) {
+			continue
+		}
+		competitor := findCompetitor(c.proc, id)
+		if entity.IsJuryKind(k) || slices.Contains(expected(competitor), k) {
+			res = append(res, fmt.Sprintf("%s (%s)", s, describe(competitor, c.cfg)))
+		}
+	}
+	return res
+}
+
+func (c *Console) completeParam(kind, id, partial string) []string {
+	var candidates []string
+	switch kind {
+	case "2":
+		candidates = []string{"<startTime HH:MM:SS.sss>"}
+	case "5":
+		for line := 1; line <= c.cfg.FiringLines; line++ {
+			candidates = append(candidates, strconv.Itoa(line))
+		}
+	case "6":
+		hit := make(map[int]bool)
+		if n, err := strconv.ParseInt(id, 10, 64); err == nil {
+			if competitor := findCompetitor(c.proc, n); competitor != nil {
+				r, _ := openRange(competitor)
+				for _, h := range r.Hits {
+					hit[h.Target] = true
+				}
+			}
+		}
+		for target := 1; target <= entity.TargetsPerRange; target++ {
+			if !hit[target] {
+				candidates = append(candidates, strconv.Itoa(target))
+			}
+		}
+	case "11":
+		candidates = []string{"<comment>"}
+	case "12":
+		candidates = []string{"<+HH:MM:SS reason>"}
+	case "13":
+		candidates = []string{"DSQ", "DNF", "DNS", "OTL", entity.Reinstate}
+	case "14":
+		candidates = []string{"<line time=HH:MM:SS.sss|param=value reason>"}
+	}
+
+	var res []string
+	for _, s := range candidates {
+		if strings.HasPrefix(s, "<") || strings.HasPrefix(s, partial) {
+			res = append(res, s)
+		}
+	}
+	return res
+}
@@ -0,0 +1,432 @@
+// Package console is an interactive console for officials entering events
+// by hand when the timing system fails.
+package console
+
+import (
+	"biathlon/config"
+	"biathlon/internal/entity"
+	"biathlon/internal/eventlog"
+	"biathlon/internal/processor"
+	"biathlon/internal/util"
+	"biathlon/internal/validator"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const help = `enter an event as [HH:MM:SS.sss] kind competitor [params], the time defaults to the clock
+  ok          commit the pending event
+  cancel      discard the pending event
+  log [n]     show the last n log entries (10 by default)
+  standings   show the standings
+  quit        leave the console
+end a line with ? to list the completions`
+
+type eventValidator interface {
+	Validate(rawData string) error
+	Line() int
+}
+
+// Console previews every entered event on a copy of the race and passes
+// it to the validator only when the operator commits it.
+type Console struct {
+	logger *zap.Logger
+	cfg    *config.Config
+	now    func() time.Time
+	out    io.Writer
+	// events receives the committed lines, newline is set when the loaded
+	// events did not end with a line break.
+	events  io.Writer
+	newline bool
+
+	proc    processor.Processor
+	v       eventValidator
+	logged  []*entity.Event
+	lines   []string
+	pending string
+}
+
+// New creates a console writing to out and appending the committed events
+// to events if not nil. The time of day of now is the default timestamp.
+func New(logger *zap.Logger, cfg *config.Config, out, events io.Writer, now func() time.Time) *Console {
+	c := &Console{
+		logger: logger,
+		cfg:    cfg,
+		now:    now,
+		out:    out,
+		events: events,
+	}
+	c.reset()
+	return c
+}
+
+// reset starts the race over with no events processed.
+func (c *Console) reset() {
+	c.proc = processor.New(c.cfg, c.logger)
+	c.proc.Subscribe(func(_ *entity.Event, logged []*entity.Event, _ error) {
+		c.logged = append(c.logged, logged...)
+	})
+	c.v = validator.New(c.logger, c.cfg, c.proc)
+}
+
+// Load processes the events entered before the console was started.
+func (c *Console) Load(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	c.newline = len(data) > 0 && data[len(data)-1] != '\n'
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		c.lines = append(c.lines, scanner.Text())
+		err = c.v.Validate(scanner.Text())
+		if err != nil {
+			c.logger.Error("failed to validate event", zap.Int("line", c.v.Line()), zap.Error(err))
+		}
+	}
+	c.logged = nil
+	return scanner.Err()
+}
+
+// Run reads the operator input until quit or the end of the input.
+func (c *Console) Run(r io.Reader) error {
+	_, err := fmt.Fprintf(c.out, "%d events loaded, type help for the commands\n", len(c.lines))
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(r)
+	for {
+		err = c.prompt()
+		if err != nil {
+			return err
+		}
+		if !scanner.Scan() {
+			break
+		}
+
+		quit, err := c.Exec(scanner.Text())
+		if err != nil {
+			return err
+		}
+		if quit {
+			return nil
+		}
+	}
+
+	if c.pending != "" {
+		_, err = fmt.Fprintf(c.out, "\npending event discarded: %s\n", c.pending)
+		if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (c *Console) prompt() error {
+	if c.pending != "" {
+		_, err := fmt.Fprintf(c.out, "[%s] pending> ", c.clock())
+		return err
+	}
+	_, err := fmt.Fprintf(c.out, "[%s]> ", c.clock())
+	return err
+}
+
+func (c *Console) clock() string {
+	return c.now().Format("15:04:05.000")
+}
+
+// Exec executes a command or previews an event, it reports whether the
+// operator quit. Only writing the output or the events fails.
+func (c *Console) Exec(line string) (bool, error) {
+	line = strings.TrimSpace(line)
+	if strings.HasSuffix(line, "?") {
+		completions := c.Complete(strings.TrimSuffix(line, "?"))
+		if len(completions) == 0 {
+			completions = []string{"no completions"}
+		}
+		return false, c.writeLines(completions)
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		if c.pending != "" {
+			return false, c.writeLines([]string{"pending: " + c.pending, "commit with ok, cancel or enter a corrected event"})
+		}
+		return false, nil
+	}
+
+	switch fields[0] {
+	case "help":
+		return false, c.writeLines([]string{help})
+	case "quit", "exit":
+		return true, nil
+	case "ok", "commit":
+		return false, c.commit()
+	case "cancel":
+		if c.pending == "" {
+			return false, c.writeLines([]string{"nothing to cancel"})
+		}
+		c.pending = ""
+		return false, c.writeLines([]string{"pending event discarded"})
+	case "log":
+		n := 10
+		if len(fields) > 1 {
+			if v, err := strconv.Atoi(fields[1]); err == nil && v > 0 {
+				n = v
+			}
+		}
+		return false, c.writeLog(n)
+	case "standings":
+		var res []string
+		for _, s := range c.proc.GetStandings() {
+			res = append(res, s.GetResult())
+		}
+		return false, c.writeLines(res)
+	}
+
+	return false, c.preview(c.withTimestamp(line))
+}
+
+// withTimestamp prefixes the event with the clock unless it has a time.
+func (c *Console) withTimestamp(line string) string {
+	if strings.HasPrefix(line, "[") {
+		return line
+	}
+	return fmt.Sprintf("[%s] %s", c.clock(), line)
+}
+
+// preview processes the event on a copy of the race and makes it pending
+// if it is valid. Events the processor accepts but that do not follow from
+// the competitor state are flagged.
+func (c *Console) preview(line string) error {
+	proc := processor.New(c.cfg, zap.NewNop())
+	v := validator.New(zap.NewNop(), c.cfg, proc)
+	for _, l := range c.lines {
+		_ = v.Validate(l)
+	}
+
+	kind, id, parsed := parseHead(line)
+	var warning string
+	if competitor := findCompetitor(proc, id); parsed && competitor != nil && !entity.IsJuryKind(kind) {
+		if kinds := expected(competitor); !slices.Contains(kinds, kind) {
+			warning = fmt.Sprintf("warning: competitor(%d) is %s, expected %s", id, describe(competitor, c.cfg), formatKinds(kinds))
+		}
+	}
+
+	var logged []*entity.Event
+	proc.Subscribe(func(_ *entity.Event, l []*entity.Event, _ error) {
+		logged = append(logged, l...)
+	})
+	err := v.Validate(line)
+
+	res := formatLogged(logged, "> ")
+	if err != nil {
+		res = append(res, "! "+err.Error())
+	}
+	if warning != "" {
+		res = append(res, warning)
+	}
+	if parsed {
+		res = append(res, state(proc, c.cfg, id))
+	}
+
+	if err != nil {
+		res = append(res, "event rejected, enter a corrected event")
+	} else {
+		c.pending = line
+		res = append(res, "commit with ok, cancel or enter a corrected event")
+	}
+	return c.writeLines(res)
+}
+
+// parseHead returns the kind and competitor of the event line.
+func parseHead(line string) (int64, int64, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return 0, 0, false
+	}
+
+	kind, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	id, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return kind, id, true
+}
+
+// state describes the competitor and the event kinds expected next from
+// the processor state.
+func state(proc processor.Processor, cfg *config.Config, id int64) string {
+	competitor := findCompetitor(proc, id)
+	if competitor == nil {
+		return fmt.Sprintf("competitor(%d) is not registered, expected %s", id, formatKinds([]int64{1}))
+	}
+	return fmt.Sprintf("competitor(%d): %s, expected %s", id, describe(competitor, cfg), formatKinds(expected(competitor)))
+}
+
+func (c *Console) commit() error {
+	if c.pending == "" {
+		return c.writeLines([]string{"nothing to commit"})
+	}
+
+	line := c.pending
+	c.pending = ""
+	c.logged = nil
+	err := c.v.Validate(line)
+	if err != nil {
+		c.logger.Error("failed to validate event", zap.Int("line", c.v.Line()), zap.Error(err))
+		res := []string{fmt.Sprintf("line %d rejected: %s", c.v.Line(), err)}
+
+		// The rejected event is not written, so the race is replayed to
+		// number the next event after the last written line.
+		c.reset()
+		for _, l := range c.lines {
+			_ = c.v.Validate(l)
+		}
+		c.logged = nil
+		return c.writeLines(res)
+	}
+
+	c.lines = append(c.lines, line)
+	res := formatLogged(c.logged, "")
+	res = append(res, fmt.Sprintf("line %d committed", c.v.Line()))
+
+	if c.events != nil {
+		if c.newline {
+			line = "\n" + line
+			c.newline = false
+		}
+		_, err = fmt.Fprintln(c.events, line)
+		if err != nil {
+			c.logger.Error("failed to write event", zap.Error(err))
+			return err
+		}
+	}
+	return c.writeLines(res)
+}
+
+func (c *Console) writeLog(n int) error {
+	log := c.proc.GetLog()
+	var res []string
+	for _, e := range log[max(len(log)-n, 0):] {
+		res = append(res, fmt.Sprintf("%4d %s", e.Line, formatEvent(e)))
+	}
+	return c.writeLines(res)
+}
+
+func (c *Console) writeLines(lines []string) error {
+	for _, l := range lines {
+		_, err := fmt.Fprintf(c.out, "  %s\n", l)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatEvent(e *entity.Event) string {
+	var b strings.Builder
+	_ = eventlog.NewWriter(&b, eventlog.FormatText).Write(e)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// formatLogged formats the log entries with the prefix. The entries a
+// recomputation of the race removed follow its entry marked with a minus,
+// the added ones are logged after them.
+func formatLogged(logged []*entity.Event, prefix string) []string {
+	var res []string
+	for _, e := range logged {
+		res = append(res, prefix+formatEvent(e))
+		if e.Recompute == nil {
+			continue
+		}
+
+		for _, removed := range e.Recompute.Removed {
+			res = append(res, prefix+"- "+formatEvent(removed))
+		}
+	}
+	return res
+}
+
+func findCompetitor(proc processor.Processor, id int64) *entity.Competitior {
+	for _, s := range proc.GetStandings() {
+		if s.Competitor.ID == id {
+			return s.Competitor
+		}
+	}
+	return nil
+}
+
+// describe summarizes where the competitor is in the race.
+func describe(c *entity.Competitior, cfg *config.Config) string {
+	switch c.Status {
+	case entity.StatusStarted:
+	case entity.StatusFinished:
+		return fmt.Sprintf("%s in %s", c.Status, util.FormatDuration(c.TotalTime()))
+	default:
+		return c.Status.String()
+	}
+
+	res := fmt.Sprintf("%s, lap %d of %d", c.Status, c.LapCounter+1, cfg.Laps)
+	if r, ok := openRange(c); ok {
+		return res + fmt.Sprintf(", on firing line %d with %d/%d hit", r.FiringLine, len(r.Hits), entity.TargetsPerRange)
+	}
+	if inPenalty(c) {
+		return res + ", in the penalty loop"
+	}
+	return res
+}
+
+func openRange(c *entity.Competitior) (entity.RangeData, bool) {
+	n := len(c.RangeData)
+	if n == 0 || !c.RangeData[n-1].Exit.IsZero() {
+		return entity.RangeData{}, false
+	}
+	return c.RangeData[n-1], true
+}
+
+func inPenalty(c *entity.Competitior) bool {
+	n := len(c.PenaltyLapData)
+	return n > 0 && c.PenaltyLapData[n-1].FinishLap.IsZero()
+}
+
+// expected returns the event kinds that may follow in the competitor
+// state. Jury decisions are possible in any state but are listed only for
+// competitors who left the course.
+func expected(c *entity.Competitior) []int64 {
+	switch c.Status {
+	case entity.StatusRegistered:
+		return []int64{2, 3, 4}
+	case entity.StatusStarted:
+	default:
+		return []int64{12, 13, 14}
+	}
+
+	if _, ok := openRange(c); ok {
+		return []int64{6, 7}
+	}
+	if inPenalty(c) {
+		return []int64{9}
+	}
+	return []int64{5, 8, 10, 11}
+}
+
+func formatKinds(kinds []int64) string {
+	var res []string = make([]string, len(kinds))
+	for i, k := range kinds {
+		res[i] = fmt.Sprintf("%d (%s)", k, kindNames[k])
+	}
+	return strings.Join(res, ", ")
+}
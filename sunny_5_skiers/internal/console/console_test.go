@@ -0,0 +1,139 @@
+package console
+
+import (
+	"biathlon/config"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestConsole(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Config{Laps: 2, LapLen: 1000, PenaltyLen: 100, FiringLines: 2, Start: "10:00:00.000", StartDelta: "00:01:00"}
+	clock := func() time.Time {
+		return time.Date(2026, 1, 10, 10, 1, 30, 0, time.UTC)
+	}
+	loaded := strings.Join([]string{
+		"[09:30:00.000] 1 1",
+		"[09:30:00.000] 1 2",
+		"[09:40:00.000] 2 1 10:00:00.000",
+		"[09:40:00.000] 2 2 10:01:00.000",
+		"[10:00:00.000] 4 1",
+	}, "\n")
+
+	start := func(t *testing.T) (*Console, *strings.Builder, *strings.Builder) {
+		var out, events strings.Builder
+		c := New(zap.NewNop(), cfg, &out, &events, clock)
+		require.NoError(t, c.Load(strings.NewReader(loaded)))
+		return c, &out, &events
+	}
+	exec := func(t *testing.T, c *Console, out *strings.Builder, line string) string {
+		out.Reset()
+		quit, err := c.Exec(line)
+		require.NoError(t, err)
+		require.False(t, quit)
+		return out.String()
+	}
+
+	t.Run("commit test", func(t *testing.T) {
+		t.Parallel()
+		c, out, events := start(t)
+
+		preview := exec(t, c, out, "4 2")
+		require.Contains(t, preview, "  > [10:01:30.000] The competitor(2) has started\n")
+		require.Contains(t, preview, "competitor(2): Started, lap 1 of 2, expected 5 (entered the firing range)")
+		require.Empty(t, events.String(), "nothing is written before the commit")
+		require.Len(t, c.proc.GetLog(), 5)
+
+		require.Equal(t, "  [10:01:30.000] The competitor(2) has started\n  line 6 committed\n", exec(t, c, out, "ok"))
+		require.Equal(t, "\n[10:01:30.000] 4 2\n", events.String(), "the loaded events did not end with a line break")
+		require.Len(t, c.proc.GetLog(), 6)
+		require.Contains(t, exec(t, c, out, "ok"), "nothing to commit")
+	})
+
+	t.Run("rejected commit test", func(t *testing.T) {
+		t.Parallel()
+		c, out, events := start(t)
+
+		// The race changed after the preview, so the pending event fails.
+		c.pending = "[10:01:30.000] 4 3"
+		require.Equal(t, "  line 6 rejected: competitor not found\n", exec(t, c, out, "ok"))
+		require.Empty(t, events.String(), "the rejected event is not written")
+		require.Len(t, c.proc.GetLog(), 5)
+
+		exec(t, c, out, "4 2")
+		require.Contains(t, exec(t, c, out, "ok"), "line 6 committed")
+		require.Equal(t, "\n[10:01:30.000] 4 2\n", events.String())
+	})
+
+	t.Run("correction test", func(t *testing.T) {
+		t.Parallel()
+		c, out, events := start(t)
+
+		rejected := exec(t, c, out, "[10:03:00.000] 5 1 3")
+		require.Contains(t, rejected, "! number of fire line is more then the amount of firelines\n")
+		require.Contains(t, rejected, "event rejected")
+		require.Contains(t, exec(t, c, out, "ok"), "nothing to commit")
+
+		require.Contains(t, exec(t, c, out, "[10:03:00.000] 5 1 1"), "on firing line 1 with 0/5 hit, expected 6 (target hit), 7 (left the firing range)")
+		require.Contains(t, exec(t, c, out, "[10:03:00.000] 5 1 2"), "on firing line 2")
+		exec(t, c, out, "ok")
+		require.Equal(t, "\n[10:03:00.000] 5 1 2\n", events.String(), "the corrected event is committed")
+
+		warning := exec(t, c, out, "[10:03:05.000] 9 1")
+		require.Contains(t, warning, "warning: competitor(1) is Started, lap 1 of 2, on firing line 2 with 0/5 hit, expected 6 (target hit), 7 (left the firing range)")
+		require.Contains(t, warning, "! competitor is not in the penalty loop\n")
+
+		exec(t, c, out, "[10:03:05.000] 7 1")
+		require.Contains(t, exec(t, c, out, "cancel"), "pending event discarded")
+		require.Empty(t, c.pending)
+	})
+
+	t.Run("recompute test", func(t *testing.T) {
+		t.Parallel()
+		c, out, _ := start(t)
+
+		exec(t, c, out, "[10:02:30.000] 4 2")
+		require.Contains(t, exec(t, c, out, "ok"), "  [10:02:30.000] The competitor(2) is disqualified: late-start\n")
+
+		preview := exec(t, c, out, "[10:05:00.000] 13 2 REINSTATE start gate malfunction")
+		require.Contains(t, preview, "  > [10:05:00.000] The race was recomputed after line 7: removed 1 and added 0 log entries\n"+
+			"  > - [10:02:30.000] The competitor(2) is disqualified: late-start\n")
+		require.Contains(t, exec(t, c, out, "ok"), "  - [10:02:30.000] The competitor(2) is disqualified: late-start\n")
+	})
+
+	t.Run("complete test", func(t *testing.T) {
+		t.Parallel()
+		c, out, _ := start(t)
+
+		require.Equal(t, []string{"standings"}, c.Complete("st"))
+		require.Equal(t, []string{"1 (registered)", "10 (ended the lap)", "11 (can't continue)", "12 (jury time penalty)",
+			"13 (jury status change)", "14 (jury event correction)"}, c.Complete("1"))
+		require.Equal(t, []string{"3 (next free)"}, c.Complete("[10:01:30.000] 1 "))
+		require.Equal(t, []string{"2 (Registered)"}, c.Complete("4 "))
+		require.Equal(t, []string{"1 (Started, lap 1 of 2)"}, c.Complete("5 "))
+		require.Equal(t, []string{"1", "2"}, c.Complete("5 1 "))
+		require.Equal(t, "  no completions\n", exec(t, c, out, "7 ?"))
+
+		exec(t, c, out, "[10:03:00.000] 5 1 1")
+		exec(t, c, out, "ok")
+		exec(t, c, out, "[10:03:05.000] 6 1 2")
+		exec(t, c, out, "ok")
+		require.Equal(t, []string{"1", "3", "4", "5"}, c.Complete("6 1 "))
+	})
+
+	t.Run("run test", func(t *testing.T) {
+		t.Parallel()
+		c, out, events := start(t)
+		out.Reset()
+
+		require.NoError(t, c.Run(strings.NewReader("4 2\nok\n5 2 1\n")))
+		require.True(t, strings.HasPrefix(out.String(), "5 events loaded"))
+		require.Contains(t, out.String(), "[10:01:30.000] pending> ")
+		require.True(t, strings.HasSuffix(out.String(), "pending event discarded: [10:01:30.000] 5 2 1\n"))
+		require.Equal(t, "\n[10:01:30.000] 4 2\n", events.String())
+	})
+}
@@ -0,0 +1,232 @@
+// Package dashboard renders the live race state for operators on an ANSI
+// terminal.
+package dashboard
+
+import (
+	"biathlon/internal/entity"
+	"biathlon/internal/eventlog"
+	"biathlon/internal/processor"
+	"biathlon/internal/util"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// logSize and errorSize limit the kept event log and validation
+	// errors, older entries scroll out.
+	logSize   = 500
+	errorSize = 100
+	// sideRows is the number of rows of the sections that do not grow
+	// and the rows every section gets before the rest is shared.
+	sideRows = 5
+
+	clearScreen = "\x1b[H\x1b[2J"
+	bold        = "\x1b[1m"
+	red         = "\x1b[31m"
+	reset       = "\x1b[0m"
+)
+
+// Dashboard collects the accepted events from the processor and the
+// validation errors of the ingest. It is not safe for concurrent use, the
+// events are expected to be processed and rendered by the same goroutine.
+type Dashboard struct {
+	proc   processor.Processor
+	log    []string
+	errors []string
+	events int
+	clock  time.Time
+}
+
+func New(proc processor.Processor) *Dashboard {
+	d := &Dashboard{proc: proc}
+	proc.Subscribe(d.listen)
+	return d
+}
+
+func (d *Dashboard) listen(event *entity.Event, logged []*entity.Event, _ error) {
+	if event.Kind == entity.KindRecompute {
+		// The recomputation changed entries already shown, so the log is
+		// taken from the processor again.
+		d.log = d.log[:0]
+		for _, e := range d.proc.GetLog() {
+			d.log = appendLimited(d.log, formatEvent(e), logSize)
+		}
+		return
+	}
+
+	d.events++
+	if event.Timestamp.After(d.clock) || d.events == 1 {
+		d.clock = event.Timestamp
+	}
+
+	for _, e := range logged {
+		d.log = appendLimited(d.log, formatEvent(e), logSize)
+	}
+}
+
+func formatEvent(e *entity.Event) string {
+	var b strings.Builder
+	_ = eventlog.NewWriter(&b, eventlog.FormatText).Write(e)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Fail records the validation error of the incoming event on the line.
+func (d *Dashboard) Fail(line int, err error) {
+	d.errors = appendLimited(d.errors, fmt.Sprintf("line %d: %s", line, err), errorSize)
+}
+
+func appendLimited(s []string, v string, limit int) []string {
+	s = append(s, v)
+	if len(s) > limit {
+		s = s[len(s)-limit:]
+	}
+	return s
+}
+
+// section is a titled block of the screen. Growing sections share the
+// room left by the others, tail sections show their latest rows.
+type section struct {
+	title string
+	rows  []string
+	style string
+	grow  bool
+	tail  bool
+}
+
+// Render clears the terminal and draws the dashboard fitted to the width
+// and height in characters.
+func (d *Dashboard) Render(w io.Writer, width, height int) error {
+	standings := d.proc.GetStandings()
+	header := fmt.Sprintf("BIATHLON  clock %s  events %d  validation errors %d",
+		util.FormatTimestamp(d.clock), d.events, len(d.errors))
+
+	sections := []section{
+		{title: "STANDINGS", rows: standingRows(standings), grow: true},
+		{title: "ON RANGE", rows: rangeRows(standings)},
+		{title: "IN PENALTY LOOP", rows: penaltyRows(standings)},
+		{title: "VALIDATION ERRORS", rows: d.errors, style: red, tail: true},
+		{title: "EVENT LOG", rows: d.log, grow: true, tail: true},
+	}
+
+	// Every section gets its title and up to sideRows rows first, then the
+	// growing sections share the rest of the screen.
+	free := height - 1 - len(sections)
+	shown := make([]int, len(sections))
+	growing := 0
+	for i, s := range sections {
+		shown[i] = min(len(s.rows), sideRows, max(free, 0))
+		free -= max(shown[i], 1)
+		if s.grow {
+			growing++
+		}
+	}
+	for i, s := range sections {
+		if !s.grow {
+			continue
+		}
+		extra := min(len(s.rows)-shown[i], max(free, 0)/growing)
+		shown[i] += extra
+		free -= extra
+		growing--
+	}
+
+	var b strings.Builder
+	b.WriteString(clearScreen)
+	b.WriteString(bold + fit(header, width) + reset + "\n")
+	for i, s := range sections {
+		b.WriteString(bold + fit(s.title, width) + reset + "\n")
+		if len(s.rows) == 0 {
+			b.WriteString(fit("  none", width) + "\n")
+			continue
+		}
+
+		rows := s.rows[:shown[i]]
+		if s.tail {
+			rows = s.rows[len(s.rows)-shown[i]:]
+		}
+		for _, r := range rows {
+			b.WriteString(s.style + fit("  "+r, width))
+			if s.style != "" {
+				b.WriteString(reset)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// fit truncates the line to the width.
+func fit(s string, width int) string {
+	r := []rune(s)
+	if width > 0 && len(r) > width {
+		return string(r[:width])
+	}
+	return s
+}
+
+func standingRows(standings []entity.Standing) []string {
+	var res []string = make([]string, len(standings))
+	for i, s := range standings {
+		c := s.Competitor
+		rank := "-"
+		if s.Rank > 0 {
+			rank = fmt.Sprint(s.Rank)
+		}
+
+		var detail string
+		switch c.Status {
+		case entity.StatusFinished:
+			detail = fmt.Sprintf("%s %s", util.FormatDuration(c.TotalTime()), util.FormatGap(s.Gap))
+		case entity.StatusStarted:
+			detail = fmt.Sprintf("lap %d", c.LapCounter+1)
+			if c.Projection != nil {
+				detail += fmt.Sprintf(", projected %s (%d)", util.FormatDuration(c.Projection.TotalTime), s.ProjectedRank)
+			}
+		case entity.StatusLapped:
+			detail = fmt.Sprintf("+%d LAP", s.LapsBehind)
+		default:
+			detail = c.StatusReason
+		}
+		res[i] = fmt.Sprintf("%3s %5d %-13s %5s %s", rank, c.ID, c.Status, fmt.Sprintf("%d/%d", c.HitedTargets, c.TotalTargets), detail)
+	}
+	return res
+}
+
+func rangeRows(standings []entity.Standing) []string {
+	var res []string
+	for _, s := range standings {
+		c := s.Competitor
+		n := len(c.RangeData)
+		if c.Status != entity.StatusStarted || n == 0 || !c.RangeData[n-1].Exit.IsZero() {
+			continue
+		}
+
+		r := c.RangeData[n-1]
+		res = append(res, fmt.Sprintf("%5d firing line %d since %s, %d/%d hit",
+			c.ID, r.FiringLine, util.FormatTimestamp(r.Enter), len(r.Hits), entity.TargetsPerRange))
+	}
+	return res
+}
+
+func penaltyRows(standings []entity.Standing) []string {
+	var res []string
+	for _, s := range standings {
+		c := s.Competitor
+		n := len(c.PenaltyLapData)
+		if c.Status != entity.StatusStarted || n == 0 || !c.PenaltyLapData[n-1].FinishLap.IsZero() {
+			continue
+		}
+
+		misses := 0
+		if r := len(c.RangeData); r > 0 {
+			misses = entity.TargetsPerRange - len(c.RangeData[r-1].Hits)
+		}
+		res = append(res, fmt.Sprintf("%5d since %s, %d loops",
+			c.ID, util.FormatTimestamp(c.PenaltyLapData[n-1].StartLap), misses))
+	}
+	return res
+}
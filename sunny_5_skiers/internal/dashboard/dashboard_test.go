@@ -0,0 +1,89 @@
+package dashboard
+
+import (
+	"biathlon/config"
+	"biathlon/internal/processor"
+	"biathlon/internal/validator"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestDashboard(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Config{Laps: 2, LapLen: 1000, PenaltyLen: 100, FiringLines: 1, Start: "10:00:00.000", StartDelta: "00:01:00"}
+
+	feed := func(t *testing.T, events ...string) *Dashboard {
+		l := zap.NewNop()
+		proc := processor.New(cfg, l)
+		board := New(proc)
+		v := validator.New(l, cfg, proc)
+		for _, e := range events {
+			if err := v.Validate(e); err != nil {
+				board.Fail(v.Line(), err)
+			}
+		}
+		return board
+	}
+	render := func(t *testing.T, board *Dashboard, width, height int) []string {
+		var b strings.Builder
+		require.NoError(t, board.Render(&b, width, height))
+		require.True(t, strings.HasPrefix(b.String(), clearScreen))
+		return strings.Split(strings.TrimSuffix(strings.TrimPrefix(b.String(), clearScreen), "\n"), "\n")
+	}
+
+	race := []string{
+		"[09:30:00.000] 1 1",
+		"[09:30:00.000] 1 2",
+		"[09:40:00.000] 2 1 10:00:00.000",
+		"[09:40:00.000] 2 2 10:01:00.000",
+		"[10:00:00.000] 4 1",
+		"[10:01:00.000] 4 2",
+		"[10:03:00.000] 5 1 1",
+		"[10:03:10.000] 6 1 1",
+		"[10:03:30.000] 7 1",
+		"[10:03:40.000] 8 1",
+		"[10:04:00.000] 5 2 1",
+		"garbage",
+	}
+
+	t.Run("sections test", func(t *testing.T) {
+		t.Parallel()
+		lines := render(t, feed(t, race...), 80, 40)
+		screen := strings.Join(lines, "\n")
+
+		require.Equal(t, bold+"BIATHLON  clock 10:04:00.000  events 11  validation errors 1"+reset, lines[0])
+		require.Contains(t, screen, bold+"ON RANGE"+reset+"\n      2 firing line 1 since 10:04:00.000, 0/5 hit\n")
+		require.Contains(t, screen, bold+"IN PENALTY LOOP"+reset+"\n      1 since 10:03:40.000, 4 loops\n")
+		require.Contains(t, screen, red+"  line 12: incorrect data format"+reset)
+		require.Equal(t, "  [10:04:00.000] The competitor(2) is on the firing range(1)", lines[len(lines)-1])
+	})
+
+	t.Run("recompute test", func(t *testing.T) {
+		t.Parallel()
+		board := feed(t, append(race[:4:4], "[10:02:30.000] 4 2", "[10:05:00.000] 13 2 REINSTATE start gate malfunction")...)
+		screen := strings.Join(render(t, board, 120, 40), "\n")
+
+		require.NotContains(t, screen, "disqualified", "the overturned disqualification is taken out of the log")
+		require.Contains(t, screen, "  [10:05:00.000] The jury reinstated the competitor(2): start gate malfunction")
+		require.Contains(t, screen, "events 6")
+	})
+
+	t.Run("fit test", func(t *testing.T) {
+		t.Parallel()
+		var events []string = make([]string, 0, 50)
+		for i := 1; i <= 50; i++ {
+			events = append(events, fmt.Sprintf("[09:30:00.000] 1 %d", i))
+		}
+
+		lines := render(t, feed(t, events...), 30, 20)
+		require.Len(t, lines, 20)
+		for _, l := range lines {
+			require.LessOrEqual(t, len([]rune(strings.NewReplacer(bold, "", reset, "", red, "").Replace(l))), 30)
+		}
+		require.Equal(t, "  [09:30:00.000] The competito", lines[19], "the latest log entry is shown")
+	})
+}
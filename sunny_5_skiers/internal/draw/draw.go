@@ -0,0 +1,130 @@
+package draw
+
+import (
+	"biathlon/internal/util"
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry is a roster line. Competitors of a lower seeding group start
+// before competitors of a higher one.
+type Entry struct {
+	CompetitorID int64
+	Group        int
+}
+
+type Slot struct {
+	CompetitorID int64
+	Group        int
+	StartTime    time.Time
+}
+
+// ReadRoster parses lines of "competitorID [group]". Empty lines and
+// lines starting with '#' are skipped, the default group is 1.
+func ReadRoster(r io.Reader) ([]Entry, error) {
+	var res []Entry = make([]Entry, 0)
+	seen := make(map[int64]struct{})
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("line %d: %w", n, ErrIncorrectRoster)
+		}
+
+		id, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		if _, ok := seen[id]; ok {
+			return nil, fmt.Errorf("line %d: %w: %d", n, ErrDuplicateCompetitor, id)
+		}
+		seen[id] = struct{}{}
+
+		entry := Entry{CompetitorID: id, Group: 1}
+		if len(fields) == 2 {
+			entry.Group, err = strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+		}
+		res = append(res, entry)
+	}
+
+	return res, scanner.Err()
+}
+
+// Draw shuffles competitors within their seeding groups using the seed and
+// assigns start times from start with the given interval. The same roster
+// and seed always produce the same start list.
+func Draw(roster []Entry, start time.Time, delta time.Duration, seed uint64) []Slot {
+	entries := slices.Clone(roster)
+	slices.SortStableFunc(entries, func(i, j Entry) int {
+		return cmp.Compare(i.Group, j.Group)
+	})
+
+	rnd := rand.New(rand.NewPCG(seed, 0))
+	for lo := 0; lo < len(entries); {
+		hi := lo
+		for hi < len(entries) && entries[hi].Group == entries[lo].Group {
+			hi++
+		}
+
+		group := entries[lo:hi]
+		rnd.Shuffle(len(group), func(i, j int) {
+			group[i], group[j] = group[j], group[i]
+		})
+		lo = hi
+	}
+
+	var res []Slot = make([]Slot, len(entries))
+	for i, e := range entries {
+		res[i] = Slot{
+			CompetitorID: e.CompetitorID,
+			Group:        e.Group,
+			StartTime:    start.Add(time.Duration(i) * delta),
+		}
+	}
+
+	return res
+}
+
+func WriteStartList(w io.Writer, slots []Slot) error {
+	for i, s := range slots {
+		_, err := fmt.Fprintf(w, "%d %s %d (group %d)\n", i+1, util.FormatTimestamp(s.StartTime), s.CompetitorID, s.Group)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteEvents writes the draw results as incoming events of kind 2
+// announced at the given time.
+func WriteEvents(w io.Writer, slots []Slot, at time.Time) error {
+	for _, s := range slots {
+		_, err := fmt.Fprintf(w, "[%s] 2 %d %s\n", util.FormatTimestamp(at), s.CompetitorID, util.FormatTimestamp(s.StartTime))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	ErrIncorrectRoster     = errors.New("incorrect roster line format")
+	ErrDuplicateCompetitor = errors.New("duplicate competitor in roster")
+)
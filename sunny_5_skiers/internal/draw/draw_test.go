@@ -0,0 +1,53 @@
+package draw
+
+import (
+	"biathlon/internal/util"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDraw(t *testing.T) {
+	t.Parallel()
+	start, err := util.ConvertToTimestamp("10:00:00.000")
+	require.NoError(t, err)
+
+	roster, err := ReadRoster(strings.NewReader("# bib group\n1 2\n2 1\n3\n4 2\n\n5 1\n6 2\n"))
+	require.NoError(t, err)
+	require.Len(t, roster, 6)
+
+	t.Run("reproducible draw", func(t *testing.T) {
+		t.Parallel()
+		first := Draw(roster, start, 30*time.Second, 42)
+		require.Equal(t, first, Draw(roster, start, 30*time.Second, 42))
+
+		for i, s := range first {
+			require.Equal(t, start.Add(time.Duration(i)*30*time.Second), s.StartTime)
+			if i < 3 {
+				require.Equal(t, 1, s.Group)
+			} else {
+				require.Equal(t, 2, s.Group)
+			}
+		}
+	})
+
+	t.Run("event stream", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		slots := []Slot{{CompetitorID: 7, Group: 1, StartTime: start}}
+		require.NoError(t, WriteEvents(&buf, slots, start.Add(-time.Hour)))
+		require.Equal(t, "[09:00:00.000] 2 7 10:00:00.000\n", buf.String())
+	})
+
+	t.Run("incorrect roster", func(t *testing.T) {
+		t.Parallel()
+		_, err := ReadRoster(strings.NewReader("1\n1\n"))
+		require.ErrorIs(t, err, ErrDuplicateCompetitor)
+
+		_, err = ReadRoster(strings.NewReader("1 2 3\n"))
+		require.ErrorIs(t, err, ErrIncorrectRoster)
+	})
+}
@@ -0,0 +1,146 @@
+package entity
+
+import (
+	"biathlon/internal/util"
+	"fmt"
+	"slices"
+)
+
+// KindEdit and KindRecompute are the outgoing kinds of the log entries of
+// an edit and of a recomputation of the race.
+const (
+	KindEdit      = 38
+	KindRecompute = 39
+)
+
+// EventEdit is an edit of the incoming event stream after it was
+// processed. Old is nil for an insertion and New for a retraction.
+type EventEdit struct {
+	Old    *Event
+	New    *Event
+	Reason string
+}
+
+// EditEvent returns the log entry of the edit of the incoming event on
+// the line.
+func EditEvent(line int, edit *EventEdit) *Event {
+	event, action := edit.New, "replaced"
+	switch {
+	case edit.Old == nil:
+		action = "inserted"
+	case edit.New == nil:
+		event, action = edit.Old, "retracted"
+	}
+
+	comment := fmt.Sprintf("The event on line %d was %s", line, action)
+	if edit.Reason != "" {
+		comment += ": " + edit.Reason
+	}
+	return &Event{
+		Timestamp:       event.Timestamp,
+		Kind:            KindEdit,
+		CompetitorID:    event.CompetitorID,
+		AdditionalParam: edit.Reason,
+		Comment:         comment,
+		Line:            line,
+		Edit:            edit,
+	}
+}
+
+// LogChange is the difference between the log before and after the race
+// was recomputed, both in log order.
+type LogChange struct {
+	Removed []*Event
+	Added   []*Event
+}
+
+// logEntry is the content of a log entry compared between two logs.
+type logEntry struct {
+	timestamp       string
+	kind            int64
+	competitorID    int64
+	additionalParam string
+	comment         string
+	line            int
+}
+
+func entryOf(e *Event) logEntry {
+	return logEntry{util.FormatTimestamp(e.Timestamp), e.Kind, e.CompetitorID, e.AdditionalParam, e.Comment, e.Line}
+}
+
+// DiffLog returns the entries missing from the log after the change and
+// the entries missing from the log before it.
+func DiffLog(before, after []*Event) *LogChange {
+	missing := func(from, in []*Event) []*Event {
+		counts := make(map[logEntry]int, len(in))
+		for _, e := range in {
+			counts[entryOf(e)]++
+		}
+
+		var res []*Event
+		for _, e := range from {
+			entry := entryOf(e)
+			if counts[entry] == 0 {
+				res = append(res, e)
+				continue
+			}
+			counts[entry]--
+		}
+		return res
+	}
+	return &LogChange{Removed: missing(before, after), Added: missing(after, before)}
+}
+
+// RecomputeEvent returns the log entry of the recomputation of the race
+// caused by the event, the entry is not a part of the log.
+func RecomputeEvent(cause *Event, change *LogChange) *Event {
+	return &Event{
+		Timestamp:    cause.Timestamp,
+		Kind:         KindRecompute,
+		CompetitorID: cause.CompetitorID,
+		Comment: fmt.Sprintf("The race was recomputed after line %d: removed %d and added %d log entries",
+			cause.Line, len(change.Removed), len(change.Added)),
+		Line:      cause.Line,
+		Recompute: change,
+	}
+}
+
+// ResultChange is a competitor result line that differs between two
+// computations of the race. Empty Before or After means the competitor
+// is missing from those results.
+type ResultChange struct {
+	CompetitorID int64  `json:"competitor"`
+	Before       string `json:"before"`
+	After        string `json:"after"`
+}
+
+// DiffStandings returns the changed results ordered by competitor ID.
+func DiffStandings(before, after []Standing) []ResultChange {
+	lines := func(standings []Standing) map[int64]string {
+		res := make(map[int64]string, len(standings))
+		for _, s := range standings {
+			res[s.Competitor.ID] = s.GetResult()
+		}
+		return res
+	}
+	a, b := lines(before), lines(after)
+
+	ids := make([]int64, 0, len(a)+len(b))
+	for id := range a {
+		ids = append(ids, id)
+	}
+	for id := range b {
+		if _, ok := a[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	var res []ResultChange
+	for _, id := range ids {
+		if a[id] != b[id] {
+			res = append(res, ResultChange{CompetitorID: id, Before: a[id], After: b[id]})
+		}
+	}
+	return res
+}
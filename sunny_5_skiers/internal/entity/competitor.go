@@ -8,6 +8,9 @@ import (
 	"time"
 )
 
+// TargetsPerRange is the number of targets shot at on a range visit.
+const TargetsPerRange = 5
+
 type LapData struct {
 	StartLap  time.Time
 	FinishLap time.Time
@@ -15,10 +18,42 @@ type LapData struct {
 }
 
 func (l *LapData) toString() string {
-	dur := l.FinishLap.Sub(l.StartLap)
+	dur, ok := l.Duration()
+	if !ok {
+		return "{,}"
+	}
 	return fmt.Sprintf("{%s, %.3f}", util.FormatDuration(dur), util.GetAverageSpeed(dur, l.Size))
 }
 
+// Duration returns the time taken to complete the lap, false if
+// the lap is not completed.
+func (l *LapData) Duration() (time.Duration, bool) {
+	if l.FinishLap.IsZero() {
+		return 0, false
+	}
+	return l.FinishLap.Sub(l.StartLap), true
+}
+
+type RangeData struct {
+	FiringLine int
+	Enter      time.Time
+	Exit       time.Time
+	Hits       []Hit
+}
+
+// Hit is a target hit during a range visit.
+type Hit struct {
+	Target int
+	Time   time.Time
+}
+
+// Projection is the estimated total time of a competitor on the course.
+type Projection struct {
+	TotalTime time.Duration
+	// At is the time of the event the projection was made on.
+	At time.Time
+}
+
 type Competitior struct {
 	ID                 int64
 	Penalty            int
@@ -27,16 +62,107 @@ type Competitior struct {
 	LapCounter         int
 	MainLapsData       []LapData
 	PenaltyLapData     []LapData
+	RangeData          []RangeData
+	Splits             []Split
 	FinishRaceTime     time.Time
 	ScheduledStartTime time.Time
-	Status             string
+	TimePenalty        time.Duration
+	Status             Status
+	StatusReason       string
+	StatusEvent        *Event
+	Rule               Rule
+	Projection         *Projection
+}
+
+// SetStatus changes the status of the competitor remembering the reason
+// and the event that caused the change.
+func (c *Competitior) SetStatus(status Status, reason string, cause *Event) {
+	c.Status = status
+	c.StatusReason = reason
+	c.StatusEvent = cause
+}
+
+func (c *Competitior) Disqualify(rule Rule, reason string, cause *Event) {
+	c.SetStatus(StatusDSQ, reason, cause)
+	c.Rule = rule
+}
+
+// ReportStatus is the status shown in the report, competitors who
+// have not started by the end of the race are reported as DNS.
+func (c *Competitior) ReportStatus() Status {
+	if c.Status == StatusRegistered {
+		return StatusDNS
+	}
+	return c.Status
 }
 
 func (c *Competitior) getTotalTime() string {
-	if c.Status != "Finished" {
-		return fmt.Sprintf("[%s]", c.Status)
+	if c.Status != StatusFinished {
+		return fmt.Sprintf("[%s]", c.ReportStatus())
 	}
-	return util.GetTimeDiffString(c.FinishRaceTime, c.ScheduledStartTime)
+	return util.FormatDuration(c.TotalTime())
+}
+
+// TotalTime is the race time including the difference between
+// scheduled and actual start time and time penalties set by the jury.
+func (c *Competitior) TotalTime() time.Duration {
+	return util.GetTimeDiff(c.FinishRaceTime, c.ScheduledStartTime) + c.TimePenalty
+}
+
+// LapCourseTime returns the time of the lap without the range visits and
+// penalty loops entered during the lap, false if the lap is not completed.
+// Laps are numbered from one.
+func (c *Competitior) LapCourseTime(lap int) (time.Duration, bool) {
+	if lap < 1 || lap > len(c.MainLapsData) {
+		return 0, false
+	}
+
+	l := c.MainLapsData[lap-1]
+	res, ok := l.Duration()
+	if !ok {
+		return 0, false
+	}
+
+	within := func(t time.Time) bool {
+		return !t.Before(l.StartLap) && t.Before(l.FinishLap)
+	}
+	for _, r := range c.RangeData {
+		if !r.Exit.IsZero() && within(r.Enter) {
+			res -= r.Exit.Sub(r.Enter)
+		}
+	}
+	for _, p := range c.PenaltyLapData {
+		if d, ok := p.Duration(); ok && within(p.StartLap) {
+			res -= d
+		}
+	}
+	return res, true
+}
+
+// CourseTime is the total time without the range time, the penalty loop
+// time and the time penalties set by the jury, false if the competitor
+// has not finished.
+func (c *Competitior) CourseTime() (time.Duration, bool) {
+	if c.Status != StatusFinished {
+		return 0, false
+	}
+
+	res := c.TotalTime() - c.TimePenalty
+	for _, r := range c.RangeData {
+		if !r.Exit.IsZero() {
+			res -= r.Exit.Sub(r.Enter)
+		}
+	}
+	for _, p := range c.PenaltyLapData {
+		if d, ok := p.Duration(); ok {
+			res -= d
+		}
+	}
+	return res, true
+}
+
+func (c *Competitior) Misses() int {
+	return c.TotalTargets - c.HitedTargets
 }
 
 func (c *Competitior) getLapsData() string {
@@ -56,9 +182,13 @@ func (c *Competitior) getPenaltySumDuration() time.Duration {
 }
 
 func (c *Competitior) GetResult() string {
+	return c.formatResult(c.getTotalTime())
+}
+
+func (c *Competitior) formatResult(total string) string {
 	sum := c.getPenaltySumDuration()
 	return fmt.Sprintf("%s %d %s {%s, %.3f} %d/%d",
-		c.getTotalTime(),
+		total,
 		c.ID,
 		c.getLapsData(),
 		util.FormatDuration(sum),
@@ -67,8 +197,46 @@ func (c *Competitior) GetResult() string {
 		c.TotalTargets)
 }
 
+// Standing is a row of the final report. Rank is zero for competitors
+// who did not finish, Gap is the time behind the winner and LapsBehind
+// is the number of laps a lapped competitor did not complete.
+type Standing struct {
+	Rank       int
+	Gap        time.Duration
+	LapsBehind int
+	// ProjectedRank is the rank the competitor on the course would get
+	// with the projected total time among the finishers and the other
+	// competitors on the course, zero without a projection.
+	ProjectedRank int
+	// CourseRank is the rank by the course time among the finishers and
+	// LapCourseRanks the ranks by the course time of every completed lap.
+	CourseRank     int
+	LapCourseRanks []int
+	Competitor     *Competitior
+}
+
+// CourseRanking is a row of the ranking by course time, the time skied
+// without the range visits and penalty loops.
+type CourseRanking struct {
+	Rank         int
+	CompetitorID int64
+	CourseTime   time.Duration
+	Gap          time.Duration
+}
+
+func (s *Standing) GetResult() string {
+	if s.Rank == 0 {
+		return fmt.Sprintf("- %s", s.Competitor.formatResult(s.Competitor.getTotalTime()+" -"))
+	}
+	if s.LapsBehind > 0 {
+		return fmt.Sprintf("%d %s", s.Rank, s.Competitor.formatResult(fmt.Sprintf("%s +%d LAP", s.Competitor.getTotalTime(), s.LapsBehind)))
+	}
+	return fmt.Sprintf("%d %s", s.Rank, s.Competitor.formatResult(s.Competitor.getTotalTime()+" "+util.FormatGap(s.Gap)))
+}
+
 var (
 	ErrCompetitorNotFound     = errors.New("competitor not found")
 	ErrCompetitorAlreadyExist = errors.New("competitor already exist")
 	ErrCompetitorDisqualified = errors.New("competitior disqualified")
+	ErrCompetitorOffCourse    = errors.New("competitor already left the course")
 )
@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"biathlon/internal/util"
 	"errors"
 	"fmt"
 	"time"
@@ -12,14 +13,64 @@ type Event struct {
 	CompetitorID    int64
 	AdditionalParam string
 	Comment         string
+	Line            int
+	// Edit is set on the log entries of edits of the incoming events.
+	Edit *EventEdit
+	// Recompute is set on the log entries of recomputations of the race.
+	Recompute *LogChange
 }
 
-func DisqualificationEvent(competitorID int64, timestamp time.Time) *Event {
+// Incoming returns the event in the incoming events file format.
+func (e *Event) Incoming() string {
+	res := fmt.Sprintf("[%s] %d %d", util.FormatTimestamp(e.Timestamp), e.Kind, e.CompetitorID)
+	if e.AdditionalParam != "" {
+		res += " " + e.AdditionalParam
+	}
+	return res
+}
+
+// DescribeIncoming returns the log message of an incoming event.
+func DescribeIncoming(e *Event) (string, error) {
+	switch e.Kind {
+	case 1:
+		return fmt.Sprintf("The competitor(%d) registered", e.CompetitorID), nil
+	case 2:
+		return fmt.Sprintf("The start time for the competitor(%d) was set by a draw to %s", e.CompetitorID, e.AdditionalParam), nil
+	case 3:
+		return fmt.Sprintf("The competitor(%d) is on the start line", e.CompetitorID), nil
+	case 4:
+		return fmt.Sprintf("The competitor(%d) has started", e.CompetitorID), nil
+	case 5:
+		return fmt.Sprintf("The competitor(%d) is on the firing range(%s)", e.CompetitorID, e.AdditionalParam), nil
+	case 6:
+		return fmt.Sprintf("The target(%s) has been hit by competitior(%d)", e.AdditionalParam, e.CompetitorID), nil
+	case 7:
+		return fmt.Sprintf("The competitor(%d) left the firing range", e.CompetitorID), nil
+	case 8:
+		return fmt.Sprintf("The competitor(%d) entered the penalty laps", e.CompetitorID), nil
+	case 9:
+		return fmt.Sprintf("The competitor(%d) left the penalty laps", e.CompetitorID), nil
+	case 10:
+		return fmt.Sprintf("The competitor(%d) ended the main lap", e.CompetitorID), nil
+	case 11:
+		return fmt.Sprintf("The competitor(%d) can`t continue: %s", e.CompetitorID, e.AdditionalParam), nil
+	case KindTimePenalty, KindStatusChange, KindCorrection:
+		decision, err := ParseJuryDecision(e)
+		if err != nil {
+			return "", err
+		}
+		return decision.describe(e.CompetitorID), nil
+	}
+	return "", ErrUnexpectedKind
+}
+
+func DisqualificationEvent(competitorID int64, timestamp time.Time, rule Rule) *Event {
 	return &Event{
-		Timestamp:    timestamp,
-		Kind:         32,
-		CompetitorID: competitorID,
-		Comment:      fmt.Sprintf("The competitor(%d) is disqualified", competitorID),
+		Timestamp:       timestamp,
+		Kind:            32,
+		CompetitorID:    competitorID,
+		AdditionalParam: string(rule),
+		Comment:         fmt.Sprintf("The competitor(%d) is disqualified: %s", competitorID, rule),
 	}
 }
 
@@ -32,6 +83,47 @@ func FinishEvent(competitorID int64, timestamp time.Time) *Event {
 	}
 }
 
+func NotStartedEvent(competitorID int64, timestamp time.Time) *Event {
+	return &Event{
+		Timestamp:    timestamp,
+		Kind:         34,
+		CompetitorID: competitorID,
+		Comment:      fmt.Sprintf("The competitor(%d) did not start", competitorID),
+	}
+}
+
+func NotFinishedEvent(competitorID int64, timestamp time.Time, reason string) *Event {
+	return &Event{
+		Timestamp:       timestamp,
+		Kind:            35,
+		CompetitorID:    competitorID,
+		AdditionalParam: reason,
+		Comment:         fmt.Sprintf("The competitor(%d) did not finish: %s", competitorID, reason),
+	}
+}
+
+func OverTimeLimitEvent(competitorID int64, timestamp time.Time, reason string) *Event {
+	return &Event{
+		Timestamp:       timestamp,
+		Kind:            36,
+		CompetitorID:    competitorID,
+		AdditionalParam: reason,
+		Comment:         fmt.Sprintf("The competitor(%d) is over the time limit: %s", competitorID, reason),
+	}
+}
+
+func LappedEvent(competitorID int64, timestamp time.Time, laps int) *Event {
+	return &Event{
+		Timestamp:       timestamp,
+		Kind:            37,
+		CompetitorID:    competitorID,
+		AdditionalParam: fmt.Sprint(laps),
+		Comment:         fmt.Sprintf("The competitor(%d) is lapped after %d laps and pulled out", competitorID, laps),
+	}
+}
+
 var (
-	ErrUnexpectedKind = errors.New("unexpected event kind")
+	ErrUnexpectedKind   = errors.New("unexpected event kind")
+	ErrNotOnRange       = errors.New("competitor is not on the firing range")
+	ErrNotInPenaltyLoop = errors.New("competitor is not in the penalty loop")
 )
@@ -0,0 +1,134 @@
+package entity
+
+import (
+	"biathlon/internal/util"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	KindTimePenalty  = 12
+	KindStatusChange = 13
+	KindCorrection   = 14
+)
+
+// Reinstate is the status change value that overturns the latest
+// status change of the competitor.
+const Reinstate = "REINSTATE"
+
+// JuryDecision is the parsed parameter of a jury event:
+//
+//	12 <competitorID> [+|-]HH:MM:SS reason, a zero penalty is rejected
+//	13 <competitorID> <DSQ|DNF|DNS|OTL|REINSTATE> reason
+//	14 <competitorID> <line> time=HH:MM:SS.sss|param=value reason
+type JuryDecision struct {
+	Penalty   time.Duration
+	Status    Status
+	Reinstate bool
+	Line      int
+	Field     string
+	Value     string
+	Reason    string
+}
+
+func IsJuryKind(kind int64) bool {
+	return kind >= KindTimePenalty && kind <= KindCorrection
+}
+
+func ParseJuryDecision(e *Event) (*JuryDecision, error) {
+	fields := strings.Fields(e.AdditionalParam)
+	if len(fields) < 2 {
+		return nil, ErrIncorrectJuryDecision
+	}
+
+	res := &JuryDecision{}
+	switch e.Kind {
+	case KindTimePenalty:
+		value, sign := fields[0], time.Duration(1)
+		if strings.HasPrefix(value, "-") {
+			sign = -1
+		}
+		d, err := util.ConvertToDuration(strings.TrimLeft(value, "+-"))
+		if err != nil || d == 0 {
+			return nil, ErrIncorrectJuryDecision
+		}
+		res.Penalty = sign * d
+		res.Reason = strings.Join(fields[1:], " ")
+	case KindStatusChange:
+		if fields[0] == Reinstate {
+			res.Reinstate = true
+		} else {
+			status, ok := ParseStatus(fields[0])
+			if !ok || (status != StatusDSQ && status != StatusDNF && status != StatusDNS && status != StatusOTL) {
+				return nil, ErrIncorrectJuryDecision
+			}
+			res.Status = status
+		}
+		res.Reason = strings.Join(fields[1:], " ")
+	case KindCorrection:
+		if len(fields) < 3 {
+			return nil, ErrIncorrectJuryDecision
+		}
+
+		line, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, ErrIncorrectJuryDecision
+		}
+
+		field, value, ok := strings.Cut(fields[1], "=")
+		if !ok || (field != "time" && field != "param") {
+			return nil, ErrIncorrectJuryDecision
+		}
+
+		if field == "time" {
+			if _, err = util.ConvertToTimestamp(value); err != nil {
+				return nil, ErrIncorrectJuryDecision
+			}
+		}
+
+		res.Line, res.Field, res.Value = line, field, value
+		res.Reason = strings.Join(fields[2:], " ")
+	default:
+		return nil, ErrUnexpectedKind
+	}
+
+	return res, nil
+}
+
+func (j *JuryDecision) describe(competitorID int64) string {
+	switch {
+	case j.Field != "":
+		return fmt.Sprintf("The jury corrected the %s of the event on line %d for the competitor(%d) to %s: %s",
+			j.Field, j.Line, competitorID, j.Value, j.Reason)
+	case j.Reinstate:
+		return fmt.Sprintf("The jury reinstated the competitor(%d): %s", competitorID, j.Reason)
+	case j.Penalty != 0:
+		sign := "+"
+		if j.Penalty < 0 {
+			sign = "-"
+		}
+		return fmt.Sprintf("The jury set a %s%s time penalty for the competitor(%d): %s",
+			sign, util.FormatDuration(j.Penalty), competitorID, j.Reason)
+	}
+	return fmt.Sprintf("The jury changed the status of the competitor(%d) to %s: %s", competitorID, j.Status, j.Reason)
+}
+
+// Apply returns a copy of the event with the correction applied.
+func (j *JuryDecision) Apply(e *Event) *Event {
+	res := *e
+	switch j.Field {
+	case "time":
+		res.Timestamp, _ = util.ConvertToTimestamp(j.Value)
+	case "param":
+		res.AdditionalParam = j.Value
+	}
+	return &res
+}
+
+var (
+	ErrIncorrectJuryDecision = errors.New("incorrect jury decision format")
+	ErrEventNotFound         = errors.New("event not found")
+)
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+type PointKind int
+
+const (
+	PointRangeEntry PointKind = iota
+	PointRangeExit
+	PointLapEnd
+)
+
+// TimingPoint identifies an intermediate point of the race. Index is the
+// lap number for lap ends and the range visit number for range points.
+type TimingPoint struct {
+	Kind  PointKind
+	Index int
+}
+
+func (t TimingPoint) String() string {
+	switch t.Kind {
+	case PointRangeEntry:
+		return fmt.Sprintf("range %d in", t.Index)
+	case PointRangeExit:
+		return fmt.Sprintf("range %d out", t.Index)
+	case PointLapEnd:
+		return fmt.Sprintf("lap %d", t.Index)
+	}
+	return fmt.Sprintf("point(%d) %d", t.Kind, t.Index)
+}
+
+type Split struct {
+	Point        TimingPoint
+	CompetitorID int64
+	Timestamp    time.Time
+	Elapsed      time.Duration
+}
+
+// SplitRanking is a single row of the virtual standings at a timing point.
+// PositionChange is positive when the competitor gained places since the
+// previous timing point they passed.
+type SplitRanking struct {
+	Position       int
+	CompetitorID   int64
+	Elapsed        time.Duration
+	Gap            time.Duration
+	PositionChange int
+}
@@ -0,0 +1,66 @@
+package entity
+
+type Status int
+
+const (
+	StatusRegistered Status = iota
+	StatusStarted
+	StatusFinished
+	StatusLapped
+	StatusOTL
+	StatusDNF
+	StatusDNS
+	StatusDSQ
+)
+
+var statusNames = map[Status]string{
+	StatusRegistered: "Registered",
+	StatusStarted:    "Started",
+	StatusFinished:   "Finished",
+	StatusLapped:     "Lapped",
+	StatusOTL:        "OverTimeLimit",
+	StatusDNF:        "NotFinished",
+	StatusDNS:        "NotStarted",
+	StatusDSQ:        "Disqualified",
+}
+
+var statusCodes = map[Status]string{
+	StatusLapped: "LAP",
+	StatusOTL:    "OTL",
+	StatusDNF:    "DNF",
+	StatusDNS:    "DNS",
+	StatusDSQ:    "DSQ",
+}
+
+func (s Status) String() string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// Code returns the short result code of a non-finisher status such as
+// DNS or DSQ, it is empty for competitors who are racing or finished.
+func (s Status) Code() string {
+	return statusCodes[s]
+}
+
+// ParseStatus accepts both status names and codes.
+func ParseStatus(s string) (Status, bool) {
+	for status, name := range statusNames {
+		if name == s || statusCodes[status] == s {
+			return status, true
+		}
+	}
+	return 0, false
+}
+
+// Rule references the competition rule a disqualification is based on.
+type Rule string
+
+const (
+	RuleLateRegistration Rule = "late-registration"
+	RuleLateStart        Rule = "late-start"
+	RulePenaltyLoop      Rule = "penalty-loop-not-completed"
+	RuleJury             Rule = "jury-decision"
+)
@@ -0,0 +1,94 @@
+package eventlog
+
+import (
+	"biathlon/internal/entity"
+	"biathlon/internal/util"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+type Format string
+
+const (
+	FormatText  Format = "text"
+	FormatJSONL Format = "jsonl"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case FormatText, FormatJSONL:
+		return Format(s), nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownFormat, s)
+}
+
+// Record is a structured representation of a single log entry.
+type Record struct {
+	Time         string `json:"time"`
+	Kind         int64  `json:"kind"`
+	CompetitorID int64  `json:"competitor"`
+	Params       string `json:"params,omitempty"`
+	Message      string `json:"message"`
+	Line         int    `json:"line,omitempty"`
+	// Old and New are the incoming events of an edit.
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+func NewRecord(e *entity.Event) Record {
+	record := Record{
+		Time:         util.FormatTimestamp(e.Timestamp),
+		Kind:         e.Kind,
+		CompetitorID: e.CompetitorID,
+		Params:       e.AdditionalParam,
+		Message:      e.Comment,
+		Line:         e.Line,
+	}
+	if e.Edit != nil && e.Edit.Old != nil {
+		record.Old = e.Edit.Old.Incoming()
+	}
+	if e.Edit != nil && e.Edit.New != nil {
+		record.New = e.Edit.New.Incoming()
+	}
+	return record
+}
+
+type Writer struct {
+	w      io.Writer
+	format Format
+	enc    *json.Encoder
+}
+
+func NewWriter(w io.Writer, format Format) *Writer {
+	return &Writer{
+		w:      w,
+		format: format,
+		enc:    json.NewEncoder(w),
+	}
+}
+
+func (w *Writer) Write(e *entity.Event) error {
+	switch w.format {
+	case FormatJSONL:
+		return w.enc.Encode(NewRecord(e))
+	case FormatText:
+		_, err := fmt.Fprintf(w.w, "[%s] %s\n", util.FormatTimestamp(e.Timestamp), e.Comment)
+		return err
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownFormat, w.format)
+}
+
+func (w *Writer) WriteAll(events []*entity.Event) error {
+	for _, e := range events {
+		if err := w.Write(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	ErrUnknownFormat = errors.New("unknown log format")
+)
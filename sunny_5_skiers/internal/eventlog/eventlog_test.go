@@ -0,0 +1,51 @@
+package eventlog
+
+import (
+	"biathlon/internal/entity"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+	event := &entity.Event{
+		Timestamp:       time.Time{}.Add(10*time.Hour + 8*time.Minute + 49289*time.Millisecond),
+		Kind:            5,
+		CompetitorID:    1,
+		AdditionalParam: "1",
+		Comment:         "The competitor(1) is on the firing range(1)",
+		Line:            21,
+	}
+
+	t.Run("text format", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, NewWriter(&buf, FormatText).Write(event))
+		require.Equal(t, "[10:08:49.289] The competitor(1) is on the firing range(1)\n", buf.String())
+	})
+
+	t.Run("jsonl format", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, NewWriter(&buf, FormatJSONL).WriteAll([]*entity.Event{event, event}))
+
+		lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+		require.Len(t, lines, 2)
+
+		var rec Record
+		require.NoError(t, json.Unmarshal(lines[0], &rec))
+		require.Equal(t, NewRecord(event), rec)
+		require.Equal(t, "10:08:49.289", rec.Time)
+		require.Equal(t, 21, rec.Line)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		t.Parallel()
+		_, err := ParseFormat("xml")
+		require.ErrorIs(t, err, ErrUnknownFormat)
+	})
+}
@@ -0,0 +1,200 @@
+// Package metrics exposes the ingest and race progress metrics in the
+// Prometheus text format.
+package metrics
+
+import (
+	"biathlon/internal/entity"
+	"biathlon/internal/processor"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// latencyBuckets are the upper bounds in seconds of the processing
+// latency histogram.
+var latencyBuckets = []float64{0.00001, 0.00005, 0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+
+type eventValidator interface {
+	Validate(rawData string) error
+}
+
+// Metrics counts the incoming events and reads the race state from the
+// processor when scraped. The processor is only used under the lock, so
+// events have to be passed through Validate.
+type Metrics struct {
+	mu        sync.Mutex
+	proc      processor.Processor
+	now       func() time.Time
+	lastEvent time.Time
+
+	registry  *prometheus.Registry
+	handler   http.Handler
+	received  prometheus.Counter
+	processed *prometheus.CounterVec
+	failures  *prometheus.CounterVec
+	latency   prometheus.Histogram
+}
+
+func New(proc processor.Processor, now func() time.Time) *Metrics {
+	m := &Metrics{
+		proc:     proc,
+		now:      now,
+		registry: prometheus.NewRegistry(),
+		received: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "biathlon_events_received_total",
+			Help: "Incoming event lines passed to the validator.",
+		}),
+		processed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "biathlon_events_processed_total",
+			Help: "Incoming events accepted by the processor by kind.",
+		}, []string{"kind"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "biathlon_validation_failures_total",
+			Help: "Rejected incoming events by error type.",
+		}, []string{"error"}),
+		latency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "biathlon_event_processing_seconds",
+			Help:    "Time to validate and process an incoming event.",
+			Buckets: latencyBuckets,
+		}),
+	}
+	m.registry.MustRegister(m.received, m.processed, m.failures, m.latency, newRaceCollector(m))
+	m.handler = promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+
+	proc.Subscribe(m.listen)
+	return m
+}
+
+func (m *Metrics) listen(event *entity.Event, _ []*entity.Event, err error) {
+	// A recomputation follows the jury decision already counted.
+	if err != nil || event.Kind == entity.KindRecompute {
+		return
+	}
+	m.processed.WithLabelValues(strconv.FormatInt(event.Kind, 10)).Inc()
+}
+
+// Validate passes the incoming line to the validator and records the
+// processing time and the validation failure if any.
+func (m *Metrics) Validate(v eventValidator, rawData string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	start := m.now()
+	err := v.Validate(rawData)
+	end := m.now()
+
+	m.latency.Observe(end.Sub(start).Seconds())
+	m.received.Inc()
+	m.lastEvent = end
+	if err != nil {
+		m.failures.WithLabelValues(errorType(err)).Inc()
+	}
+	return err
+}
+
+// errorType names the kind of a validation error by its message up to the
+// first value, so the number of label values stays bounded.
+func errorType(err error) string {
+	var timeErr *time.ParseError
+	var numErr *strconv.NumError
+	switch {
+	case errors.As(err, &timeErr):
+		return "invalid_timestamp"
+	case errors.As(err, &numErr):
+		return "invalid_number"
+	}
+
+	msg, _, _ := strings.Cut(err.Error(), "\n")
+	msg, _, _ = strings.Cut(msg, ":")
+	return strings.Join(strings.Fields(msg), "_")
+}
+
+// raceCollector reports the race state and the feed activity from a
+// single snapshot per scrape, the standings are computed once.
+type raceCollector struct {
+	m           *Metrics
+	competitors *prometheus.Desc
+	onRange     *prometheus.Desc
+	inPenalty   *prometheus.Desc
+	lastEvent   *prometheus.Desc
+	idle        *prometheus.Desc
+}
+
+func newRaceCollector(m *Metrics) *raceCollector {
+	return &raceCollector{
+		m:           m,
+		competitors: prometheus.NewDesc("biathlon_competitors", "Competitors by status.", []string{"status"}, nil),
+		onRange:     prometheus.NewDesc("biathlon_competitors_on_range", "Competitors on the firing range.", nil, nil),
+		inPenalty:   prometheus.NewDesc("biathlon_competitors_in_penalty_loop", "Competitors in the penalty loop.", nil, nil),
+		lastEvent: prometheus.NewDesc("biathlon_last_event_timestamp_seconds",
+			"Unix time the last incoming event was received.", nil, nil),
+		idle: prometheus.NewDesc("biathlon_feed_idle_seconds", "Seconds since the last incoming event was received.", nil, nil),
+	}
+}
+
+func (c *raceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.competitors
+	ch <- c.onRange
+	ch <- c.inPenalty
+	ch <- c.lastEvent
+	ch <- c.idle
+}
+
+// raceSnapshot is the state reported by a scrape.
+type raceSnapshot struct {
+	statuses  map[entity.Status]float64
+	onRange   float64
+	inPenalty float64
+	lastEvent float64
+	idle      float64
+}
+
+func (m *Metrics) snapshot() raceSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	res := raceSnapshot{statuses: make(map[entity.Status]float64)}
+	for _, s := range m.proc.GetStandings() {
+		c := s.Competitor
+		res.statuses[c.Status]++
+		if c.Status != entity.StatusStarted {
+			continue
+		}
+
+		if n := len(c.RangeData); n > 0 && c.RangeData[n-1].Exit.IsZero() {
+			res.onRange++
+		}
+		if n := len(c.PenaltyLapData); n > 0 && c.PenaltyLapData[n-1].FinishLap.IsZero() {
+			res.inPenalty++
+		}
+	}
+
+	// The feed gauges are zero before the first event, so a feed that
+	// never started is seen from the counter.
+	if !m.lastEvent.IsZero() {
+		res.lastEvent = float64(m.lastEvent.UnixMilli()) / 1000
+		res.idle = m.now().Sub(m.lastEvent).Seconds()
+	}
+	return res
+}
+
+func (c *raceCollector) Collect(ch chan<- prometheus.Metric) {
+	snapshot := c.m.snapshot()
+	for status := entity.StatusRegistered; status <= entity.StatusDSQ; status++ {
+		ch <- prometheus.MustNewConstMetric(c.competitors, prometheus.GaugeValue, snapshot.statuses[status], status.String())
+	}
+	ch <- prometheus.MustNewConstMetric(c.onRange, prometheus.GaugeValue, snapshot.onRange)
+	ch <- prometheus.MustNewConstMetric(c.inPenalty, prometheus.GaugeValue, snapshot.inPenalty)
+	ch <- prometheus.MustNewConstMetric(c.lastEvent, prometheus.GaugeValue, snapshot.lastEvent)
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, snapshot.idle)
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.handler.ServeHTTP(w, r)
+}
@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"biathlon/config"
+	"biathlon/internal/entity"
+	"biathlon/internal/processor"
+	"biathlon/internal/validator"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// countingProcessor counts the standings computations.
+type countingProcessor struct {
+	processor.Processor
+	standings int
+}
+
+func (p *countingProcessor) GetStandings() []entity.Standing {
+	p.standings++
+	return p.Processor.GetStandings()
+}
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Config{Laps: 2, LapLen: 1000, PenaltyLen: 100, FiringLines: 1, Start: "10:00:00.000", StartDelta: "00:01:00"}
+
+	// feed passes the events through metrics with a clock that moves by
+	// step on every reading.
+	feed := func(t *testing.T, step time.Duration, events ...string) *Metrics {
+		l := zap.NewNop()
+		proc := processor.New(cfg, l)
+		clock := time.Unix(1700000000, 0)
+		m := New(proc, func() time.Time {
+			clock = clock.Add(step)
+			return clock
+		})
+		v := validator.New(l, cfg, proc)
+		for _, e := range events {
+			_ = m.Validate(v, e)
+		}
+		return m
+	}
+	scrape := func(t *testing.T, m *Metrics) string {
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		require.Equal(t, 200, rec.Code)
+		return rec.Body.String()
+	}
+
+	race := []string{
+		"[09:30:00.000] 1 1",
+		"[09:30:00.000] 1 2",
+		"[09:30:00.000] 1 3",
+		"[09:40:00.000] 2 1 10:00:00.000",
+		"[09:40:00.000] 2 2 10:01:00.000",
+		"[10:00:00.000] 4 1",
+		"[10:01:00.000] 4 2",
+		"[10:03:00.000] 5 1 1",
+		"[10:03:10.000] 6 1 1",
+		"[10:03:30.000] 7 1",
+		"[10:03:40.000] 8 1",
+		"[10:04:00.000] 5 2 1",
+		"garbage",
+		"[10:0x:00.000] 4 2",
+		"[10:05:00.000] 4 9",
+	}
+
+	t.Run("counters test", func(t *testing.T) {
+		t.Parallel()
+		out := scrape(t, feed(t, time.Millisecond, race...))
+
+		require.Contains(t, out, "# TYPE biathlon_events_received_total counter\nbiathlon_events_received_total 15\n")
+		require.Contains(t, out, `biathlon_events_processed_total{kind="1"} 3`+"\n")
+		require.Contains(t, out, `biathlon_events_processed_total{kind="4"} 2`+"\n")
+		require.Contains(t, out, `biathlon_events_processed_total{kind="8"} 1`+"\n")
+		require.Contains(t, out, `biathlon_validation_failures_total{error="incorrect_data_format"} 1`+"\n")
+		require.Contains(t, out, `biathlon_validation_failures_total{error="invalid_timestamp"} 1`+"\n")
+		require.Contains(t, out, `biathlon_validation_failures_total{error="competitor_not_found"} 1`+"\n")
+	})
+
+	t.Run("histogram test", func(t *testing.T) {
+		t.Parallel()
+		out := scrape(t, feed(t, 2*time.Millisecond, race[:3]...))
+
+		require.Contains(t, out, "# TYPE biathlon_event_processing_seconds histogram\n")
+		require.Contains(t, out, `biathlon_event_processing_seconds_bucket{le="0.001"} 0`+"\n")
+		require.Contains(t, out, `biathlon_event_processing_seconds_bucket{le="0.005"} 3`+"\n")
+		require.Contains(t, out, `biathlon_event_processing_seconds_bucket{le="1"} 3`+"\n")
+		require.Contains(t, out, `biathlon_event_processing_seconds_bucket{le="+Inf"} 3`+"\n")
+		require.Contains(t, out, "biathlon_event_processing_seconds_sum 0.006\n")
+		require.Contains(t, out, "biathlon_event_processing_seconds_count 3\n")
+	})
+
+	t.Run("race state test", func(t *testing.T) {
+		t.Parallel()
+		out := scrape(t, feed(t, time.Millisecond, race...))
+
+		require.Contains(t, out, `biathlon_competitors{status="Registered"} 1`+"\n")
+		require.Contains(t, out, `biathlon_competitors{status="Started"} 2`+"\n")
+		require.Contains(t, out, `biathlon_competitors{status="Finished"} 0`+"\n")
+		require.Contains(t, out, "biathlon_competitors_on_range 1\n")
+		require.Contains(t, out, "biathlon_competitors_in_penalty_loop 1\n")
+	})
+
+	t.Run("snapshot test", func(t *testing.T) {
+		t.Parallel()
+		l := zap.NewNop()
+		proc := &countingProcessor{Processor: processor.New(cfg, l)}
+		m := New(proc, time.Now)
+		v := validator.New(l, cfg, proc)
+		for _, e := range race {
+			_ = m.Validate(v, e)
+		}
+
+		proc.standings = 0
+		scrape(t, m)
+		require.Equal(t, 1, proc.standings, "a scrape computes the standings once")
+	})
+
+	t.Run("feed idle test", func(t *testing.T) {
+		t.Parallel()
+		out := scrape(t, feed(t, 10*time.Second))
+		require.Contains(t, out, "biathlon_events_received_total 0\n")
+		require.Contains(t, out, "biathlon_feed_idle_seconds 0\n")
+
+		out = scrape(t, feed(t, 10*time.Second, race[0]))
+		require.Contains(t, out, "biathlon_last_event_timestamp_seconds 1.70000002e+09\n")
+		require.Contains(t, out, "biathlon_feed_idle_seconds 10\n")
+	})
+
+	t.Run("http test", func(t *testing.T) {
+		t.Parallel()
+		rec := httptest.NewRecorder()
+		feed(t, time.Millisecond, race[0]).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+		require.Equal(t, 200, rec.Code)
+		require.True(t, strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4"))
+		require.Contains(t, rec.Body.String(), "biathlon_events_received_total 1\n")
+	})
+
+	t.Run("error type test", func(t *testing.T) {
+		t.Parallel()
+		require.Equal(t, "start_time_is_already_drawn", errorType(errors.New("start time is already drawn: 10:00:00.000")))
+		require.Equal(t, "unexpected_event_kind", errorType(errors.New("unexpected event kind\nline 3")))
+	})
+}
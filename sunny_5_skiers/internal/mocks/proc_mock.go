@@ -11,6 +11,7 @@ package mocks
 
 import (
 	entity "biathlon/internal/entity"
+	processor "biathlon/internal/processor"
 	reflect "reflect"
 
 	gomock "go.uber.org/mock/gomock"
@@ -40,11 +41,37 @@ func (m *MockProcessor) EXPECT() *MockProcessorMockRecorder {
 	return m.recorder
 }
 
+// Close mocks base method.
+func (m *MockProcessor) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockProcessorMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockProcessor)(nil).Close))
+}
+
+// GetCourseTimes mocks base method.
+func (m *MockProcessor) GetCourseTimes(lap int) []entity.CourseRanking {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCourseTimes", lap)
+	ret0, _ := ret[0].([]entity.CourseRanking)
+	return ret0
+}
+
+// GetCourseTimes indicates an expected call of GetCourseTimes.
+func (mr *MockProcessorMockRecorder) GetCourseTimes(lap any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCourseTimes", reflect.TypeOf((*MockProcessor)(nil).GetCourseTimes), lap)
+}
+
 // GetLog mocks base method.
-func (m *MockProcessor) GetLog() []string {
+func (m *MockProcessor) GetLog() []*entity.Event {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetLog")
-	ret0, _ := ret[0].([]string)
+	ret0, _ := ret[0].([]*entity.Event)
 	return ret0
 }
 
@@ -68,6 +95,49 @@ func (mr *MockProcessorMockRecorder) GetResult() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResult", reflect.TypeOf((*MockProcessor)(nil).GetResult))
 }
 
+// GetSplits mocks base method.
+func (m *MockProcessor) GetSplits(point entity.TimingPoint) []entity.SplitRanking {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSplits", point)
+	ret0, _ := ret[0].([]entity.SplitRanking)
+	return ret0
+}
+
+// GetSplits indicates an expected call of GetSplits.
+func (mr *MockProcessorMockRecorder) GetSplits(point any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSplits", reflect.TypeOf((*MockProcessor)(nil).GetSplits), point)
+}
+
+// GetStandings mocks base method.
+func (m *MockProcessor) GetStandings() []entity.Standing {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStandings")
+	ret0, _ := ret[0].([]entity.Standing)
+	return ret0
+}
+
+// GetStandings indicates an expected call of GetStandings.
+func (mr *MockProcessorMockRecorder) GetStandings() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStandings", reflect.TypeOf((*MockProcessor)(nil).GetStandings))
+}
+
+// Insert mocks base method.
+func (m *MockProcessor) Insert(event *entity.Event, reason string) ([]entity.ResultChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", event, reason)
+	ret0, _ := ret[0].([]entity.ResultChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockProcessorMockRecorder) Insert(event, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockProcessor)(nil).Insert), event, reason)
+}
+
 // Process mocks base method.
 func (m *MockProcessor) Process(event *entity.Event) error {
 	m.ctrl.T.Helper()
@@ -81,3 +151,59 @@ func (mr *MockProcessorMockRecorder) Process(event any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Process", reflect.TypeOf((*MockProcessor)(nil).Process), event)
 }
+
+// Replace mocks base method.
+func (m *MockProcessor) Replace(line int, event *entity.Event, reason string) ([]entity.ResultChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replace", line, event, reason)
+	ret0, _ := ret[0].([]entity.ResultChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Replace indicates an expected call of Replace.
+func (mr *MockProcessorMockRecorder) Replace(line, event, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replace", reflect.TypeOf((*MockProcessor)(nil).Replace), line, event, reason)
+}
+
+// Retract mocks base method.
+func (m *MockProcessor) Retract(line int, reason string) ([]entity.ResultChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Retract", line, reason)
+	ret0, _ := ret[0].([]entity.ResultChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Retract indicates an expected call of Retract.
+func (mr *MockProcessorMockRecorder) Retract(line, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Retract", reflect.TypeOf((*MockProcessor)(nil).Retract), line, reason)
+}
+
+// Subscribe mocks base method.
+func (m *MockProcessor) Subscribe(l processor.Listener) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Subscribe", l)
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockProcessorMockRecorder) Subscribe(l any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockProcessor)(nil).Subscribe), l)
+}
+
+// TimingPoints mocks base method.
+func (m *MockProcessor) TimingPoints() []entity.TimingPoint {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TimingPoints")
+	ret0, _ := ret[0].([]entity.TimingPoint)
+	return ret0
+}
+
+// TimingPoints indicates an expected call of TimingPoints.
+func (mr *MockProcessorMockRecorder) TimingPoints() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TimingPoints", reflect.TypeOf((*MockProcessor)(nil).TimingPoints))
+}
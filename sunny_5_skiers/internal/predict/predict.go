@@ -0,0 +1,221 @@
+package predict
+
+import (
+	"biathlon/config"
+	"biathlon/internal/entity"
+	"biathlon/internal/processor"
+	"biathlon/internal/simulate"
+	"biathlon/internal/util"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type Options struct {
+	Runs    int
+	Seed    uint64
+	DNFRate float64
+}
+
+// Distribution summarizes the finishing times of a competitor.
+type Distribution struct {
+	Mean   string `json:"mean"`
+	StdDev string `json:"stdDev"`
+	P10    string `json:"p10"`
+	Median string `json:"median"`
+	P90    string `json:"p90"`
+}
+
+type Prediction struct {
+	CompetitorID int64   `json:"competitor"`
+	Win          float64 `json:"win"`
+	Podium       float64 `json:"podium"`
+	Finish       float64 `json:"finish"`
+	MeanRank     float64 `json:"meanRank,omitempty"`
+	// Time is the distribution of the total times of the finished races.
+	Time *Distribution `json:"time,omitempty"`
+}
+
+type Report struct {
+	Runs        int          `json:"runs"`
+	Predictions []Prediction `json:"predictions"`
+}
+
+type tally struct {
+	wins, podiums int
+	ranks         []int
+	times         []time.Duration
+}
+
+// Predict runs virtual races of the athletes through the processor and
+// estimates the outcome probabilities. Every run is simulated with its
+// own seed derived from the options seed, so reports are reproducible
+// although the runs are processed concurrently.
+func Predict(cfg *config.Config, athletes []simulate.Athlete, opts Options) (*Report, error) {
+	if opts.Runs <= 0 || len(athletes) == 0 {
+		return nil, ErrNothingToPredict
+	}
+
+	outcomes := make([][]entity.Standing, opts.Runs)
+	errs := make([]error, opts.Runs)
+	runs := make(chan int)
+	var wg sync.WaitGroup
+	for range runtime.GOMAXPROCS(0) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for run := range runs {
+				outcomes[run], errs[run] = race(cfg, athletes, opts, run)
+			}
+		}()
+	}
+	for run := range opts.Runs {
+		runs <- run
+	}
+	close(runs)
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	tallies := make(map[int64]*tally, len(athletes))
+	for _, a := range athletes {
+		tallies[a.CompetitorID] = &tally{}
+	}
+
+	for _, standings := range outcomes {
+		for _, s := range standings {
+			t := tallies[s.Competitor.ID]
+			if t == nil || s.Rank == 0 || s.Competitor.Status != entity.StatusFinished {
+				continue
+			}
+
+			t.ranks = append(t.ranks, s.Rank)
+			t.times = append(t.times, s.Competitor.TotalTime())
+			if s.Rank == 1 {
+				t.wins++
+			}
+			if s.Rank <= 3 {
+				t.podiums++
+			}
+		}
+	}
+
+	report := &Report{Runs: opts.Runs, Predictions: make([]Prediction, 0, len(athletes))}
+	for _, a := range athletes {
+		t := tallies[a.CompetitorID]
+		runs := float64(opts.Runs)
+		p := Prediction{
+			CompetitorID: a.CompetitorID,
+			Win:          float64(t.wins) / runs,
+			Podium:       float64(t.podiums) / runs,
+			Finish:       float64(len(t.times)) / runs,
+		}
+
+		if len(t.times) > 0 {
+			sum := 0
+			for _, r := range t.ranks {
+				sum += r
+			}
+			p.MeanRank = float64(sum) / float64(len(t.ranks))
+			p.Time = distribution(t.times)
+		}
+		report.Predictions = append(report.Predictions, p)
+	}
+
+	slices.SortStableFunc(report.Predictions, func(a, b Prediction) int {
+		if res := cmp.Compare(b.Win, a.Win); res != 0 {
+			return res
+		}
+		if res := cmp.Compare(b.Podium, a.Podium); res != 0 {
+			return res
+		}
+		return cmp.Compare(a.MeanRank, b.MeanRank)
+	})
+	return report, nil
+}
+
+// race simulates and processes a single virtual race.
+func race(cfg *config.Config, athletes []simulate.Athlete, opts Options, run int) ([]entity.Standing, error) {
+	events, err := simulate.Generate(cfg, simulate.Options{
+		Athletes: athletes,
+		Seed:     opts.Seed + uint64(run),
+		DNFRate:  opts.DNFRate,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	proc := processor.New(cfg, zap.NewNop())
+	for _, e := range events {
+		_ = proc.Process(e)
+	}
+	return proc.GetStandings(), nil
+}
+
+func distribution(times []time.Duration) *Distribution {
+	sorted := slices.Clone(times)
+	slices.Sort(sorted)
+
+	var sum float64
+	for _, t := range sorted {
+		sum += float64(t)
+	}
+	mean := sum / float64(len(sorted))
+
+	var variance float64
+	for _, t := range sorted {
+		variance += (float64(t) - mean) * (float64(t) - mean)
+	}
+	variance /= float64(len(sorted))
+
+	percentile := func(p float64) string {
+		return util.FormatDuration(sorted[int(p*float64(len(sorted)-1))])
+	}
+
+	return &Distribution{
+		Mean:   util.FormatDuration(time.Duration(mean)),
+		StdDev: util.FormatDuration(time.Duration(math.Sqrt(variance))),
+		P10:    percentile(0.1),
+		Median: percentile(0.5),
+		P90:    percentile(0.9),
+	}
+}
+
+// WriteSummary writes the report as a table ordered by win probability.
+func (r *Report) WriteSummary(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "%d virtual races\n%-10s %6s %7s %7s %6s %12s %12s %12s\n",
+		r.Runs, "competitor", "win", "podium", "finish", "rank", "p10", "median", "p90")
+	if err != nil {
+		return err
+	}
+
+	for _, p := range r.Predictions {
+		p10, median, p90 := "-", "-", "-"
+		rank := "-"
+		if p.Time != nil {
+			p10, median, p90 = p.Time.P10, p.Time.Median, p.Time.P90
+			rank = fmt.Sprintf("%.1f", p.MeanRank)
+		}
+
+		_, err = fmt.Fprintf(w, "%-10d %5.1f%% %6.1f%% %6.1f%% %6s %12s %12s %12s\n",
+			p.CompetitorID, p.Win*100, p.Podium*100, p.Finish*100, rank, p10, median, p90)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	ErrNothingToPredict = errors.New("prediction needs athletes and a positive number of runs")
+)
@@ -0,0 +1,54 @@
+package predict
+
+import (
+	"biathlon/config"
+	"biathlon/internal/simulate"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredict(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Config{
+		Laps: 2, LapLen: 3000, PenaltyLen: 150, FiringLines: 1,
+		Start: "10:00:00.000", StartDelta: "00:00:30",
+	}
+	athletes := []simulate.Athlete{
+		{CompetitorID: 1, Speed: 4.5, Accuracy: 0.8},
+		{CompetitorID: 2, Speed: 5.5, Accuracy: 0.95},
+		{CompetitorID: 3, Speed: 4.6, Accuracy: 0.85},
+	}
+
+	t.Run("prediction test", func(t *testing.T) {
+		t.Parallel()
+		report, err := Predict(cfg, athletes, Options{Runs: 200, Seed: 1})
+		require.NoError(t, err)
+		require.Equal(t, 200, report.Runs)
+		require.Len(t, report.Predictions, 3)
+
+		favourite := report.Predictions[0]
+		require.Equal(t, int64(2), favourite.CompetitorID)
+		require.Greater(t, favourite.Win, 0.9)
+		require.Equal(t, 1.0, favourite.Podium)
+		require.Equal(t, 1.0, favourite.Finish)
+		require.NotNil(t, favourite.Time)
+		require.Less(t, favourite.Time.P10, favourite.Time.P90)
+
+		again, err := Predict(cfg, athletes, Options{Runs: 200, Seed: 1})
+		require.NoError(t, err)
+		require.Equal(t, report, again)
+
+		var buf strings.Builder
+		require.NoError(t, report.WriteSummary(&buf))
+		require.Contains(t, buf.String(), "200 virtual races")
+		require.Len(t, strings.Split(strings.TrimSpace(buf.String()), "\n"), 5)
+	})
+
+	t.Run("no runs test", func(t *testing.T) {
+		t.Parallel()
+		_, err := Predict(cfg, athletes, Options{})
+		require.ErrorIs(t, err, ErrNothingToPredict)
+	})
+}
@@ -0,0 +1,71 @@
+package processor
+
+import (
+	"biathlon/internal/entity"
+	"cmp"
+	"slices"
+	"time"
+)
+
+// courseTime returns the course time of the lap, or of the whole race for
+// lap zero.
+func courseTime(c *entity.Competitior, lap int) (time.Duration, bool) {
+	if lap == 0 {
+		return c.CourseTime()
+	}
+	return c.LapCourseTime(lap)
+}
+
+func rankCourseTimes(competitors []*entity.Competitior, lap int) []entity.CourseRanking {
+	var res []entity.CourseRanking
+	for _, c := range competitors {
+		if d, ok := courseTime(c, lap); ok {
+			res = append(res, entity.CourseRanking{CompetitorID: c.ID, CourseTime: d})
+		}
+	}
+
+	slices.SortStableFunc(res, func(i, j entity.CourseRanking) int {
+		if i.CourseTime != j.CourseTime {
+			return cmp.Compare(i.CourseTime, j.CourseTime)
+		}
+		return cmp.Compare(i.CompetitorID, j.CompetitorID)
+	})
+
+	for i := range res {
+		res[i].Rank = i + 1
+		if i > 0 && res[i].CourseTime == res[i-1].CourseTime {
+			res[i].Rank = res[i-1].Rank
+		}
+		res[i].Gap = res[i].CourseTime - res[0].CourseTime
+	}
+	return res
+}
+
+// GetCourseTimes ranks the competitors by the course time of the lap, or
+// by the course time of the whole race for lap zero.
+func (p *processorImpl) GetCourseTimes(lap int) []entity.CourseRanking {
+	return rankCourseTimes(p.competitors, lap)
+}
+
+// courseRanks sets the overall and per lap course time ranks of the
+// standings.
+func (p *processorImpl) courseRanks(standings []entity.Standing) {
+	index := make(map[int64]int, len(standings))
+	for i, s := range standings {
+		index[s.Competitor.ID] = i
+	}
+
+	for lap := 0; lap <= p.cfg.Laps; lap++ {
+		for _, r := range rankCourseTimes(p.competitors, lap) {
+			s := &standings[index[r.CompetitorID]]
+			if lap == 0 {
+				s.CourseRank = r.Rank
+				continue
+			}
+			if s.LapCourseRanks == nil {
+				s.LapCourseRanks = make([]int, p.cfg.Laps)
+			}
+			s.LapCourseRanks[lap-1] = r.Rank
+		}
+	}
+}
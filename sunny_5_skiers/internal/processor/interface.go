@@ -5,6 +5,22 @@ import "biathlon/internal/entity"
 //go:generate mockgen -source=./interface.go -destination=../mocks/proc_mock.go -package=mocks
 type Processor interface {
 	Process(event *entity.Event) error
-	GetLog() []string
+	GetLog() []*entity.Event
 	GetResult() []string
+	GetStandings() []entity.Standing
+	TimingPoints() []entity.TimingPoint
+	GetSplits(point entity.TimingPoint) []entity.SplitRanking
+	GetCourseTimes(lap int) []entity.CourseRanking
+	Insert(event *entity.Event, reason string) ([]entity.ResultChange, error)
+	Retract(line int, reason string) ([]entity.ResultChange, error)
+	Replace(line int, event *entity.Event, reason string) ([]entity.ResultChange, error)
+	Subscribe(l Listener)
+	Close()
 }
+
+// Listener receives an incoming event together with the log entries it
+// caused, both incoming and outgoing, and the processing error if any.
+// Edits and recomputations of the race are passed as their log entries:
+// an entity.KindRecompute entry comes with the entries it added to the
+// log and holds the removed ones.
+type Listener func(event *entity.Event, logged []*entity.Event, err error)
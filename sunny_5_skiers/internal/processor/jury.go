@@ -0,0 +1,237 @@
+package processor
+
+import (
+	"biathlon/internal/entity"
+
+	"go.uber.org/zap"
+)
+
+// Automatic status changes other than disqualifications that a
+// reinstatement can overturn, named like the disqualification rules.
+const (
+	ruleNotStarted entity.Rule = "not-started"
+	ruleTimeLimit  entity.Rule = "time-limit"
+	ruleLapped     entity.Rule = "lapped"
+)
+
+// overturn is the status change a reinstatement cancels: the status change
+// or can't continue event on the line, or the automatic rule for the
+// competitor.
+type overturn struct {
+	line         int
+	competitorID int64
+	rule         entity.Rule
+}
+
+// juryOverlay holds the jury decisions that change how earlier events
+// are processed, so they have to be applied to the whole event stream.
+type juryOverlay struct {
+	// overturns are keyed by the line of the reinstatement.
+	overturns   map[int]overturn
+	corrections map[int]*entity.JuryDecision
+	// changed is set when a reinstatement found a new status change to
+	// overturn, so the race has to be replayed without it.
+	changed bool
+}
+
+func newJuryOverlay() juryOverlay {
+	return juryOverlay{
+		overturns:   make(map[int]overturn),
+		corrections: make(map[int]*entity.JuryDecision),
+	}
+}
+
+// waived reports whether the automatic rule no longer applies to the
+// competitor.
+func (j juryOverlay) waived(competitorID int64, rule entity.Rule) bool {
+	for _, o := range j.overturns {
+		if o.line == 0 && o.competitorID == competitorID && o.rule == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// overturned reports whether the status change on the line is cancelled.
+func (j juryOverlay) overturned(line int) bool {
+	for _, o := range j.overturns {
+		if o.line != 0 && o.line == line {
+			return true
+		}
+	}
+	return false
+}
+
+func (j juryOverlay) correct(event *entity.Event) *entity.Event {
+	decision, ok := j.corrections[event.Line]
+	if !ok {
+		return event
+	}
+
+	res := decision.Apply(event)
+	if comment, err := entity.DescribeIncoming(res); err == nil {
+		res.Comment = comment
+	}
+	return res
+}
+
+func (p *processorImpl) findInput(line int) *entity.Event {
+	i := p.inputIndex(line)
+	if i < 0 {
+		return nil
+	}
+	return p.input[i]
+}
+
+// processJury checks the jury decision, adds it to the event stream and
+// recomputes the race so the decision is applied retroactively.
+func (p *processorImpl) processJury(event *entity.Event) error {
+	decision, err := entity.ParseJuryDecision(event)
+	if err != nil {
+		p.logger.Error("failed to parse jury decision", zap.Error(err))
+		return err
+	}
+
+	if _, ok := p.competitorList[event.CompetitorID]; !ok {
+		err := entity.ErrCompetitorNotFound
+		p.logger.Error("failed to get competitor", zap.Error(err))
+		return err
+	}
+
+	if decision.Field != "" {
+		target := p.findInput(decision.Line)
+		if target == nil || target.CompetitorID != event.CompetitorID || entity.IsJuryKind(target.Kind) {
+			err := entity.ErrEventNotFound
+			p.logger.Error("failed to find corrected event", zap.Int("line", decision.Line), zap.Error(err))
+			return err
+		}
+	}
+
+	p.input = append(p.input, event)
+	p.rebuild()
+	return nil
+}
+
+// rebuild recomputes the race state from the incoming event stream with
+// all jury decisions applied. A reinstatement overturns the status change
+// it finds when the stream is replayed, so the race is replayed again
+// until no reinstatement finds a new one. Events rejected only after the
+// recomputation are logged, the others were reported when they were
+// processed for the first time.
+func (p *processorImpl) rebuild() {
+	p.jury = newJuryOverlay()
+	for _, e := range p.input {
+		if e.Kind != entity.KindCorrection {
+			continue
+		}
+
+		decision, err := entity.ParseJuryDecision(e)
+		if err != nil {
+			continue
+		}
+		p.jury.corrections[decision.Line] = decision
+	}
+
+	logger := p.logger
+	p.logger = zap.NewNop()
+
+	rejected := make(map[int]error)
+	for replay := true; replay; replay = p.jury.changed {
+		p.jury.changed = false
+		p.reset()
+		clear(rejected)
+		for _, e := range p.input {
+			if err := p.apply(e); err != nil {
+				rejected[e.Line] = err
+			}
+		}
+		if p.closed {
+			p.closeCourse()
+		}
+	}
+	p.logger = logger
+
+	for _, e := range p.input {
+		err, ok := rejected[e.Line]
+		if _, before := p.rejected[e.Line]; ok && !before {
+			p.logger.Warn("event rejected after the race was recomputed", zap.Int("line", e.Line), zap.Error(err))
+		}
+	}
+	p.rejected = rejected
+}
+
+// applyJury applies the decision at its position in the event stream.
+func (p *processorImpl) applyJury(event *entity.Event) error {
+	competitor, ok := p.competitorList[event.CompetitorID]
+	if !ok {
+		err := entity.ErrCompetitorNotFound
+		p.logger.Error("failed to get competitor", zap.Error(err))
+		return err
+	}
+
+	decision, err := entity.ParseJuryDecision(event)
+	if err != nil {
+		p.logger.Error("failed to parse jury decision", zap.Error(err))
+		return err
+	}
+
+	p.events = append(p.events, event)
+	switch {
+	case event.Kind == entity.KindTimePenalty:
+		competitor.TimePenalty += decision.Penalty
+	case event.Kind == entity.KindStatusChange && decision.Reinstate:
+		p.reinstate(competitor, event)
+	case event.Kind == entity.KindStatusChange:
+		p.changeStatus(competitor, decision, event)
+	}
+	return nil
+}
+
+// reinstate records the latest status change of the competitor as
+// overturned the first time the reinstatement is replayed. The state
+// after an overturn found in the same replay is outdated, so the later
+// reinstatements wait for the next one. Competitors on the course or
+// finished have nothing to overturn.
+func (p *processorImpl) reinstate(c *entity.Competitior, event *entity.Event) {
+	if _, ok := p.jury.overturns[event.Line]; ok || p.jury.changed {
+		return
+	}
+
+	o := overturn{competitorID: c.ID}
+	switch {
+	case c.Status == entity.StatusRegistered || c.Status == entity.StatusStarted || c.Status == entity.StatusFinished:
+		return
+	case c.StatusEvent != nil && (c.StatusEvent.Kind == entity.KindStatusChange || c.StatusEvent.Kind == 11):
+		o.line = c.StatusEvent.Line
+	case c.Status == entity.StatusDSQ:
+		o.rule = c.Rule
+	case c.Status == entity.StatusDNS:
+		o.rule = ruleNotStarted
+	case c.Status == entity.StatusOTL:
+		o.rule = ruleTimeLimit
+	case c.Status == entity.StatusLapped:
+		o.rule = ruleLapped
+	default:
+		return
+	}
+
+	p.jury.overturns[event.Line] = o
+	p.jury.changed = true
+}
+
+func (p *processorImpl) changeStatus(c *entity.Competitior, decision *entity.JuryDecision, event *entity.Event) {
+	switch decision.Status {
+	case entity.StatusDSQ:
+		c.Disqualify(entity.RuleJury, decision.Reason, event)
+		p.appendOutgoing(entity.DisqualificationEvent(c.ID, event.Timestamp, entity.RuleJury), event)
+	case entity.StatusDNF:
+		c.SetStatus(entity.StatusDNF, decision.Reason, event)
+		p.appendOutgoing(entity.NotFinishedEvent(c.ID, event.Timestamp, decision.Reason), event)
+	case entity.StatusDNS:
+		c.SetStatus(entity.StatusDNS, decision.Reason, event)
+		p.appendOutgoing(entity.NotStartedEvent(c.ID, event.Timestamp), event)
+	case entity.StatusOTL:
+		c.SetStatus(entity.StatusOTL, decision.Reason, event)
+		p.appendOutgoing(entity.OverTimeLimitEvent(c.ID, event.Timestamp, decision.Reason), event)
+	}
+}
@@ -0,0 +1,47 @@
+package processor
+
+import (
+	"biathlon/internal/entity"
+	"cmp"
+	"fmt"
+	"time"
+)
+
+// leaderPosition tracks the competitor furthest ahead on the course and
+// the clock time each lap was first completed.
+type leaderPosition struct {
+	competitorID int64
+	laps         []time.Time
+}
+
+func (p *processorImpl) trackLeader(c *entity.Competitior, timestamp time.Time) {
+	if c.LapCounter > len(p.leader.laps) {
+		p.leader.competitorID = c.ID
+		p.leader.laps = append(p.leader.laps, timestamp)
+	}
+}
+
+// checkLapped pulls out the competitor at the end of a lap if the leader
+// has already completed the next one, so the competitor is a full lap behind.
+func (p *processorImpl) checkLapped(c *entity.Competitior, event *entity.Event) bool {
+	if !p.cfg.PullsLapped() || c.Status != entity.StatusStarted || p.jury.waived(c.ID, ruleLapped) {
+		return false
+	}
+
+	if len(p.leader.laps) <= c.LapCounter || p.leader.laps[c.LapCounter].After(event.Timestamp) {
+		return false
+	}
+
+	c.SetStatus(entity.StatusLapped, fmt.Sprintf("lapped by competitor(%d)", p.leader.competitorID), event)
+	p.appendOutgoing(entity.LappedEvent(c.ID, event.Timestamp, c.LapCounter), event)
+	return true
+}
+
+// compareLapped orders lapped competitors by completed laps and by the
+// time they were pulled out.
+func compareLapped(a, b *entity.Competitior) int {
+	if res := cmp.Compare(b.LapCounter, a.LapCounter); res != 0 {
+		return res
+	}
+	return a.StatusEvent.Timestamp.Compare(b.StatusEvent.Timestamp)
+}
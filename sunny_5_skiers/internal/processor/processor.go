@@ -4,7 +4,9 @@ import (
 	"biathlon/config"
 	"biathlon/internal/entity"
 	"biathlon/internal/util"
+	"cmp"
 	"slices"
+	"strconv"
 	"time"
 
 	"go.uber.org/zap"
@@ -14,33 +16,143 @@ var _ Processor = (*processorImpl)(nil)
 
 type processorImpl struct {
 	competitorList map[int64]*entity.Competitior
-	events         []*entity.Event
-	cfg            *config.Config
-	logger         *zap.Logger
+	// competitors are the registered competitors ordered by ID.
+	competitors []*entity.Competitior
+	events      []*entity.Event
+	input       []*entity.Event
+	lastLine    int
+	points      []entity.TimingPoint
+	splits      map[entity.TimingPoint][]entity.Split
+	limits      timeLimits
+	leader      leaderPosition
+	jury        juryOverlay
+	// rejected are the incoming lines the race state does not include.
+	rejected map[int]error
+	// applied is the length of the log before the latest incoming event
+	// was applied, the log entries after it were caused by the event.
+	applied int
+	// closed is set once the event stream ended, the recomputed race is
+	// closed again.
+	closed    bool
+	listeners []Listener
+	cfg       *config.Config
+	logger    *zap.Logger
 }
 
 func (p *processorImpl) parseStartDelta() (time.Duration, error) {
-	t, err := time.Parse("15:04:05", p.cfg.StartDelta)
-	if err != nil {
-		return 0, err
-	}
+	return util.ConvertToDuration(p.cfg.StartDelta)
+}
 
-	return time.Duration(t.Hour())*time.Hour +
-		time.Duration(t.Minute())*time.Minute +
-		time.Duration(t.Second())*time.Second +
-		time.Duration(t.Nanosecond()), nil
+// appendOutgoing adds an event generated by the processor to the log,
+// attributing it to the source line of the incoming event that caused it.
+func (p *processorImpl) appendOutgoing(event *entity.Event, cause *entity.Event) {
+	event.Line = cause.Line
+	p.events = append(p.events, event)
 }
 
 func New(cfg *config.Config, logger *zap.Logger) *processorImpl {
-	return &processorImpl{
-		competitorList: make(map[int64]*entity.Competitior),
-		events:         make([]*entity.Event, 0),
-		cfg:            cfg,
-		logger:         logger,
+	p := &processorImpl{
+		input:    make([]*entity.Event, 0),
+		jury:     newJuryOverlay(),
+		rejected: make(map[int]error),
+		cfg:      cfg,
+		logger:   logger,
 	}
+	p.limits = p.parseTimeLimits()
+	p.reset()
+	return p
+}
+
+// reset clears the race state computed from the incoming events.
+func (p *processorImpl) reset() {
+	p.competitorList = make(map[int64]*entity.Competitior)
+	p.competitors = make([]*entity.Competitior, 0)
+	p.events = make([]*entity.Event, 0)
+	p.points = make([]entity.TimingPoint, 0)
+	p.splits = make(map[entity.TimingPoint][]entity.Split)
+	p.leader = leaderPosition{}
 }
 
+// Process applies the incoming event to the race state. Events without
+// a source line are numbered after the last line of the incoming stream.
 func (p *processorImpl) Process(event *entity.Event) error {
+	if event.Line == 0 {
+		event.Line = p.lastLine + 1
+	}
+	p.lastLine = max(p.lastLine, event.Line)
+	p.applied = len(p.events)
+	log := p.events
+
+	var err error
+	if entity.IsJuryKind(event.Kind) {
+		err = p.processJury(event)
+	} else {
+		p.input = append(p.input, event)
+		err = p.apply(event)
+		if err != nil {
+			p.rejected[event.Line] = err
+		}
+	}
+
+	p.notify(event, slices.Clip(p.events[p.applied:]), err)
+	if entity.IsJuryKind(event.Kind) && err == nil {
+		p.recomputed(event, log, p.events[:p.applied])
+	}
+	return err
+}
+
+func (p *processorImpl) notify(event *entity.Event, logged []*entity.Event, err error) {
+	for _, l := range p.listeners {
+		l(event, logged, err)
+	}
+}
+
+// Subscribe registers a listener called after every processed event and
+// every edit of the processed events.
+func (p *processorImpl) Subscribe(l Listener) {
+	p.listeners = append(p.listeners, l)
+}
+
+func (p *processorImpl) apply(event *entity.Event) error {
+	p.applied = len(p.events)
+	event = p.jury.correct(event)
+	if p.jury.overturned(event.Line) {
+		// The status change stays in the log without taking effect.
+		p.events = append(p.events, event)
+		return nil
+	}
+
+	p.checkNotStarted(event)
+	p.checkTimeLimits(event)
+
+	var overTimeLimit string
+	competitor, ok := p.competitorList[event.CompetitorID]
+	if ok && competitor.Status == entity.StatusStarted && !entity.IsJuryKind(event.Kind) {
+		overTimeLimit = p.exceededTimeLimit(competitor, event.Timestamp)
+	}
+
+	if ok && competitor.Status == entity.StatusRegistered && p.jury.waived(competitor.ID, ruleNotStarted) &&
+		event.Kind >= 5 && event.Kind <= 10 {
+		p.start(competitor, competitor.ScheduledStartTime, event)
+	}
+
+	err := p.process(event)
+	if err != nil {
+		return err
+	}
+
+	if overTimeLimit != "" {
+		p.pullOut(competitor, overTimeLimit, event)
+	}
+	return nil
+}
+
+func (p *processorImpl) start(competitor *entity.Competitior, timestamp time.Time, cause *entity.Event) {
+	competitor.SetStatus(entity.StatusStarted, "", cause)
+	competitor.MainLapsData = append(competitor.MainLapsData, entity.LapData{StartLap: timestamp, Size: p.cfg.LapLen})
+}
+
+func (p *processorImpl) process(event *entity.Event) error {
 	switch event.Kind {
 	case 1:
 		_, ok := p.competitorList[event.CompetitorID]
@@ -49,17 +161,28 @@ func (p *processorImpl) Process(event *entity.Event) error {
 			p.logger.Error("failed to register competitor", zap.Error(err))
 			return err
 		}
-		p.competitorList[event.CompetitorID] = &entity.Competitior{
+		competitor := &entity.Competitior{
 			ID:             event.CompetitorID,
-			Status:         "NotStarted",
-			TotalTargets:   p.cfg.Laps * 5,
+			Status:         entity.StatusRegistered,
+			TotalTargets:   p.cfg.Laps * entity.TargetsPerRange,
 			HitedTargets:   0,
 			LapCounter:     0,
 			MainLapsData:   make([]entity.LapData, 0),
 			PenaltyLapData: make([]entity.LapData, 0),
 			Penalty:        p.cfg.Laps * p.cfg.PenaltyLen * p.cfg.FiringLines * 5,
 		}
+		p.competitorList[event.CompetitorID] = competitor
+		i, _ := slices.BinarySearchFunc(p.competitors, competitor.ID, func(c *entity.Competitior, id int64) int {
+			return cmp.Compare(c.ID, id)
+		})
+		p.competitors = slices.Insert(p.competitors, i, competitor)
 		p.events = append(p.events, event)
+
+		start, err := util.ConvertToTimestamp(p.cfg.Start)
+		if err == nil && event.Timestamp.After(start) && !p.jury.waived(competitor.ID, entity.RuleLateRegistration) {
+			competitor.Disqualify(entity.RuleLateRegistration, "registered after the start of the race", event)
+			p.appendOutgoing(entity.DisqualificationEvent(competitor.ID, event.Timestamp, entity.RuleLateRegistration), event)
+		}
 	case 2:
 		competitor, ok := p.competitorList[event.CompetitorID]
 		if !ok {
@@ -92,18 +215,41 @@ func (p *processorImpl) Process(event *entity.Event) error {
 			return err
 		}
 
+		if competitor.Status != entity.StatusRegistered && competitor.Status != entity.StatusDNS {
+			return entity.ErrCompetitorDisqualified
+		}
+
 		delta := event.Timestamp.Sub(competitor.ScheduledStartTime)
-		if delta < 0 || delta > timeDelta {
-			competitor.Status = "NotStarted"
-			p.events = append(p.events, entity.DisqualificationEvent(competitor.ID, event.Timestamp))
+		if (delta < 0 || delta > timeDelta) && !p.jury.waived(competitor.ID, entity.RuleLateStart) {
+			p.events = append(p.events, event)
+			competitor.Disqualify(entity.RuleLateStart, "started outside of the start interval", event)
+			p.appendOutgoing(entity.DisqualificationEvent(competitor.ID, event.Timestamp, entity.RuleLateStart), event)
 			return nil
 		}
 
-		competitor.Status = "Started"
-		competitor.MainLapsData = append(competitor.MainLapsData, entity.LapData{StartLap: event.Timestamp, Size: p.cfg.LapLen})
+		p.start(competitor, event.Timestamp, event)
 		p.events = append(p.events, event)
 
 	case 5:
+		competitor, ok := p.competitorList[event.CompetitorID]
+		if !ok {
+			err := entity.ErrCompetitorNotFound
+			p.logger.Error("failed to get competitor", zap.Error(err))
+			return err
+		}
+
+		if competitor.Status != entity.StatusStarted {
+			return entity.ErrCompetitorDisqualified
+		}
+
+		firingLine, err := strconv.Atoi(event.AdditionalParam)
+		if err != nil {
+			p.logger.Error("failed to convert additional param to firing line", zap.Error(err))
+			return err
+		}
+
+		competitor.RangeData = append(competitor.RangeData, entity.RangeData{FiringLine: firingLine, Enter: event.Timestamp})
+		p.recordSplit(competitor, entity.PointRangeEntry, len(competitor.RangeData), event.Timestamp)
 		p.events = append(p.events, event)
 	case 6:
 		competitor, ok := p.competitorList[event.CompetitorID]
@@ -113,15 +259,37 @@ func (p *processorImpl) Process(event *entity.Event) error {
 			return err
 		}
 
-		if competitor.Status != "Started" {
+		if competitor.Status != entity.StatusStarted {
 			return entity.ErrCompetitorDisqualified
 		}
 
 		competitor.Penalty -= p.cfg.PenaltyLen
 		competitor.HitedTargets += 1
+		if n := len(competitor.RangeData); n > 0 && competitor.RangeData[n-1].Exit.IsZero() {
+			target, _ := strconv.Atoi(event.AdditionalParam)
+			competitor.RangeData[n-1].Hits = append(competitor.RangeData[n-1].Hits, entity.Hit{Target: target, Time: event.Timestamp})
+		}
 		p.events = append(p.events, event)
 	case 7:
+		competitor, ok := p.competitorList[event.CompetitorID]
+		if !ok {
+			err := entity.ErrCompetitorNotFound
+			p.logger.Error("failed to get competitor", zap.Error(err))
+			return err
+		}
+
+		if competitor.Status != entity.StatusStarted {
+			return entity.ErrCompetitorDisqualified
+		}
+
+		if len(competitor.RangeData) == 0 {
+			return entity.ErrNotOnRange
+		}
+
+		competitor.RangeData[len(competitor.RangeData)-1].Exit = event.Timestamp
+		p.recordSplit(competitor, entity.PointRangeExit, len(competitor.RangeData), event.Timestamp)
 		p.events = append(p.events, event)
+		p.project(competitor, event.Timestamp)
 	case 8:
 		competitor, ok := p.competitorList[event.CompetitorID]
 		if !ok {
@@ -130,7 +298,7 @@ func (p *processorImpl) Process(event *entity.Event) error {
 			return err
 		}
 
-		if competitor.Status != "Started" {
+		if competitor.Status != entity.StatusStarted {
 			return entity.ErrCompetitorDisqualified
 		}
 
@@ -144,11 +312,18 @@ func (p *processorImpl) Process(event *entity.Event) error {
 			return err
 		}
 
-		if competitor.Status != "Started" {
+		if competitor.Status != entity.StatusStarted {
 			return entity.ErrCompetitorDisqualified
 		}
 
-		competitor.PenaltyLapData[len(competitor.PenaltyLapData)-1].FinishLap = event.Timestamp
+		loops := competitor.PenaltyLapData
+		if len(loops) == 0 || !loops[len(loops)-1].FinishLap.IsZero() {
+			err := entity.ErrNotInPenaltyLoop
+			p.logger.Error("failed to finish penalty lap", zap.Error(err))
+			return err
+		}
+
+		loops[len(loops)-1].FinishLap = event.Timestamp
 		p.events = append(p.events, event)
 	case 10:
 		competitor, ok := p.competitorList[event.CompetitorID]
@@ -158,27 +333,31 @@ func (p *processorImpl) Process(event *entity.Event) error {
 			return err
 		}
 
-		if competitor.Status != "Started" {
+		if competitor.Status != entity.StatusStarted {
 			return entity.ErrCompetitorDisqualified
 		}
 
 		if len(competitor.PenaltyLapData) != 0 &&
-			competitor.PenaltyLapData[len(competitor.PenaltyLapData)-1].FinishLap.IsZero() {
-			competitor.Status = "NotFinished"
-			p.events = append(p.events, entity.DisqualificationEvent(competitor.ID, event.Timestamp))
+			competitor.PenaltyLapData[len(competitor.PenaltyLapData)-1].FinishLap.IsZero() &&
+			!p.jury.waived(competitor.ID, entity.RulePenaltyLoop) {
+			competitor.Disqualify(entity.RulePenaltyLoop, "ended the lap without leaving the penalty loop", event)
+			p.appendOutgoing(entity.DisqualificationEvent(competitor.ID, event.Timestamp, entity.RulePenaltyLoop), event)
 			return nil
 		}
 
 		competitor.MainLapsData[competitor.LapCounter].FinishLap = event.Timestamp
 		competitor.LapCounter += 1
+		p.recordSplit(competitor, entity.PointLapEnd, competitor.LapCounter, event.Timestamp)
 
 		p.events = append(p.events, event)
-		if competitor.LapCounter == p.cfg.Laps && competitor.Status == "Started" {
-			competitor.Status = "Finished"
+		p.trackLeader(competitor, event.Timestamp)
+		if competitor.LapCounter == p.cfg.Laps && competitor.Status == entity.StatusStarted {
+			competitor.SetStatus(entity.StatusFinished, "", event)
 			competitor.FinishRaceTime = event.Timestamp
-			p.events = append(p.events, entity.FinishEvent(competitor.ID, event.Timestamp))
-		} else {
+			p.appendOutgoing(entity.FinishEvent(competitor.ID, event.Timestamp), event)
+		} else if !p.checkLapped(competitor, event) {
 			competitor.MainLapsData = append(competitor.MainLapsData, entity.LapData{StartLap: event.Timestamp, Size: p.cfg.LapLen})
+			p.project(competitor, event.Timestamp)
 		}
 	case 11:
 		competitor, ok := p.competitorList[event.CompetitorID]
@@ -188,57 +367,249 @@ func (p *processorImpl) Process(event *entity.Event) error {
 			return err
 		}
 
-		competitor.Status = "NotFinished"
+		if competitor.Status != entity.StatusRegistered && competitor.Status != entity.StatusStarted {
+			err := entity.ErrCompetitorOffCourse
+			p.logger.Error("failed to mark competitor as not finished", zap.Error(err))
+			return err
+		}
+
+		competitor.SetStatus(entity.StatusDNF, event.AdditionalParam, event)
 		p.events = append(p.events, event)
-		p.events = append(p.events, entity.DisqualificationEvent(competitor.ID, event.Timestamp))
+		p.appendOutgoing(entity.NotFinishedEvent(competitor.ID, event.Timestamp, event.AdditionalParam), event)
+	case entity.KindTimePenalty, entity.KindStatusChange, entity.KindCorrection:
+		return p.applyJury(event)
 	default:
 		return entity.ErrUnexpectedKind
 	}
 	return nil
 }
 
-func (p *processorImpl) GetResult() []string {
+var tieBreakers = map[string]func(a, b *entity.Competitior) int{
+	config.TieBreakerMisses: func(a, b *entity.Competitior) int {
+		return cmp.Compare(a.Misses(), b.Misses())
+	},
+	config.TieBreakerBib: func(a, b *entity.Competitior) int {
+		return cmp.Compare(a.ID, b.ID)
+	},
+}
+
+// compareFinishers orders finishers by total time and configured
+// tie-breakers, zero means the competitors share a rank.
+func (p *processorImpl) compareFinishers(a, b *entity.Competitior) int {
+	if res := cmp.Compare(a.TotalTime(), b.TotalTime()); res != 0 {
+		return res
+	}
+
+	for _, name := range p.cfg.TieBreakers {
+		// Unknown names are rejected when the config is loaded.
+		compare, ok := tieBreakers[name]
+		if !ok {
+			continue
+		}
+		if res := compare(a, b); res != 0 {
+			return res
+		}
+	}
+	return 0
+}
+
+func (p *processorImpl) GetStandings() []entity.Standing {
 	var finished []*entity.Competitior = make([]*entity.Competitior, 0)
+	var lapped []*entity.Competitior = make([]*entity.Competitior, 0)
 	var disqualified []*entity.Competitior = make([]*entity.Competitior, 0)
 
 	for _, c := range p.competitorList {
-		if c.Status != "Finished" {
-			disqualified = append(disqualified, c)
-		} else {
+		switch c.Status {
+		case entity.StatusFinished:
 			finished = append(finished, c)
+		case entity.StatusLapped:
+			lapped = append(lapped, c)
+		default:
+			disqualified = append(disqualified, c)
 		}
 	}
 
-	slices.SortStableFunc(finished, func(i, j *entity.Competitior) int {
-		ti := i.FinishRaceTime.Sub(i.ScheduledStartTime)
-		tj := j.FinishRaceTime.Sub(j.ScheduledStartTime)
+	slices.SortFunc(finished, func(i, j *entity.Competitior) int {
+		if res := p.compareFinishers(i, j); res != 0 {
+			return res
+		}
+		return cmp.Compare(i.ID, j.ID)
+	})
+
+	slices.SortFunc(lapped, func(i, j *entity.Competitior) int {
+		if res := compareLapped(i, j); res != 0 {
+			return res
+		}
+		return cmp.Compare(i.ID, j.ID)
+	})
 
-		if ti == tj {
-			return 0
-		} else if ti > tj {
-			return 1
+	slices.SortFunc(disqualified, func(i, j *entity.Competitior) int {
+		if res := cmp.Compare(i.ReportStatus(), j.ReportStatus()); res != 0 {
+			return res
 		}
-		return -1
+		return cmp.Compare(i.ID, j.ID)
 	})
 
-	var res []string = make([]string, 0, len(p.competitorList))
+	var res []entity.Standing = make([]entity.Standing, 0, len(p.competitorList))
 
-	for _, c := range finished {
-		res = append(res, c.GetResult())
+	for i, c := range finished {
+		rank := i + 1
+		if i > 0 && p.compareFinishers(finished[i-1], c) == 0 {
+			rank = res[i-1].Rank
+		}
+		res = append(res, entity.Standing{
+			Rank:       rank,
+			Gap:        c.TotalTime() - finished[0].TotalTime(),
+			Competitor: c,
+		})
+	}
+
+	for i, c := range lapped {
+		rank := len(res) + 1
+		if i > 0 && compareLapped(lapped[i-1], c) == 0 {
+			rank = res[len(res)-1].Rank
+		}
+		res = append(res, entity.Standing{
+			Rank:       rank,
+			LapsBehind: p.cfg.Laps - c.LapCounter,
+			Competitor: c,
+		})
 	}
 
 	for _, c := range disqualified {
-		res = append(res, c.GetResult())
+		res = append(res, entity.Standing{Competitor: c})
+	}
+
+	projectRanks(res)
+	p.courseRanks(res)
+	return res
+}
+
+func (p *processorImpl) GetResult() []string {
+	standings := p.GetStandings()
+
+	var res []string = make([]string, len(standings))
+	for i, s := range standings {
+		res[i] = s.GetResult()
 	}
 
 	return res
 }
 
-func (p *processorImpl) GetLog() []string {
-	var res []string = make([]string, len(p.events))
-	for i, e := range p.events {
-		res[i] = e.Comment
+func (p *processorImpl) GetLog() []*entity.Event {
+	return slices.Clone(p.events)
+}
+
+func (p *processorImpl) sortedCompetitors() []*entity.Competitior {
+	return p.competitors
+}
+
+// checkNotStarted marks competitors whose start interval has passed
+// by the time of the event as not started. The competitor of a start
+// event is skipped as a late start is handled by the event itself.
+func (p *processorImpl) checkNotStarted(event *entity.Event) {
+	timeDelta, err := p.parseStartDelta()
+	if err != nil {
+		return
+	}
+
+	for _, c := range p.sortedCompetitors() {
+		if c.Status != entity.StatusRegistered || c.ScheduledStartTime.IsZero() || p.jury.waived(c.ID, ruleNotStarted) {
+			continue
+		}
+		if event.Kind == 4 && event.CompetitorID == c.ID {
+			continue
+		}
+		if event.Timestamp.Sub(c.ScheduledStartTime) > timeDelta {
+			c.SetStatus(entity.StatusDNS, "did not start in the start interval", event)
+			p.appendOutgoing(entity.NotStartedEvent(c.ID, event.Timestamp), event)
+		}
+	}
+}
+
+// recordSplit stores the elapsed time since the scheduled start of
+// the competitor at the given timing point.
+func (p *processorImpl) recordSplit(c *entity.Competitior, kind entity.PointKind, index int, timestamp time.Time) {
+	point := entity.TimingPoint{Kind: kind, Index: index}
+	split := entity.Split{
+		Point:        point,
+		CompetitorID: c.ID,
+		Timestamp:    timestamp,
+		Elapsed:      timestamp.Sub(c.ScheduledStartTime),
+	}
+
+	if _, ok := p.splits[point]; !ok {
+		p.points = append(p.points, point)
+	}
+	p.splits[point] = append(p.splits[point], split)
+	c.Splits = append(c.Splits, split)
+}
+
+func (p *processorImpl) TimingPoints() []entity.TimingPoint {
+	return slices.Clone(p.points)
+}
+
+// GetSplits returns the virtual standings at the timing point for all
+// competitors who have passed it so far.
+func (p *processorImpl) GetSplits(point entity.TimingPoint) []entity.SplitRanking {
+	splits := slices.Clone(p.splits[point])
+	slices.SortStableFunc(splits, func(i, j entity.Split) int {
+		if i.Elapsed != j.Elapsed {
+			return cmp.Compare(i.Elapsed, j.Elapsed)
+		}
+		return cmp.Compare(i.CompetitorID, j.CompetitorID)
+	})
+
+	var res []entity.SplitRanking = make([]entity.SplitRanking, len(splits))
+	for i, s := range splits {
+		position := i + 1
+		if i > 0 && s.Elapsed == splits[i-1].Elapsed {
+			position = res[i-1].Position
+		}
+
+		res[i] = entity.SplitRanking{
+			Position:     position,
+			CompetitorID: s.CompetitorID,
+			Elapsed:      s.Elapsed,
+			Gap:          s.Elapsed - splits[0].Elapsed,
+		}
+
+		prev, ok := p.previousPoint(s.CompetitorID, point)
+		if ok {
+			res[i].PositionChange = p.positionAt(prev, s.CompetitorID) - position
+		}
 	}
 
 	return res
 }
+
+func (p *processorImpl) previousPoint(competitorID int64, point entity.TimingPoint) (entity.TimingPoint, bool) {
+	competitor, ok := p.competitorList[competitorID]
+	if !ok {
+		return entity.TimingPoint{}, false
+	}
+
+	for i, s := range competitor.Splits {
+		if s.Point == point && i > 0 {
+			return competitor.Splits[i-1].Point, true
+		}
+	}
+	return entity.TimingPoint{}, false
+}
+
+func (p *processorImpl) positionAt(point entity.TimingPoint, competitorID int64) int {
+	var elapsed time.Duration
+	for _, s := range p.splits[point] {
+		if s.CompetitorID == competitorID {
+			elapsed = s.Elapsed
+		}
+	}
+
+	position := 1
+	for _, s := range p.splits[point] {
+		if s.Elapsed < elapsed {
+			position++
+		}
+	}
+	return position
+}
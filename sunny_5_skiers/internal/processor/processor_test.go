@@ -3,13 +3,23 @@ package processor
 import (
 	"biathlon/config"
 	"biathlon/internal/entity"
+	"biathlon/internal/util"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
+// at parses the timestamp of a test event.
+func at(t *testing.T, s string) time.Time {
+	t.Helper()
+	ts, err := util.ConvertToTimestamp(s)
+	require.NoError(t, err)
+	return ts
+}
+
 type testCase struct {
 	eventList   []*entity.Event
 	errExpected bool
@@ -368,6 +378,25 @@ func TestProcessor(t *testing.T) {
 				require.NoError(t, err)
 			}
 		}
+
+		proc := New(&config.Config{Laps: 1, StartDelta: "00:01:30"}, l)
+		events := []*entity.Event{
+			{Timestamp: at(t, "09:30:00.000"), Kind: 1, CompetitorID: 1},
+			{Timestamp: at(t, "09:40:00.000"), Kind: 2, CompetitorID: 1, AdditionalParam: "10:00:00.000"},
+			{Timestamp: at(t, "10:00:00.100"), Kind: 4, CompetitorID: 1},
+		}
+		for _, e := range events {
+			require.NoError(t, proc.Process(e))
+		}
+
+		leave := func(ts string) error {
+			return proc.Process(&entity.Event{Timestamp: at(t, ts), Kind: 9, CompetitorID: 1})
+		}
+		require.ErrorIs(t, leave("10:05:00.000"), entity.ErrNotInPenaltyLoop)
+		require.NoError(t, proc.Process(&entity.Event{Timestamp: at(t, "10:06:00.000"), Kind: 8, CompetitorID: 1}))
+		require.NoError(t, leave("10:07:00.000"))
+		require.ErrorIs(t, leave("10:08:00.000"), entity.ErrNotInPenaltyLoop)
+		require.Equal(t, at(t, "10:07:00.000"), proc.competitorList[1].PenaltyLapData[0].FinishLap)
 	})
 
 	t.Run("finish test", func(t *testing.T) {
@@ -450,4 +479,459 @@ func TestProcessor(t *testing.T) {
 			}
 		}
 	})
+	t.Run("split rankings test", func(t *testing.T) {
+		t.Parallel()
+		base, err := util.ConvertToTimestamp("10:00:00.000")
+		require.NoError(t, err)
+		proc := New(&config.Config{Laps: 2, LapLen: 1000, StartDelta: "00:01:30"}, l)
+		events := []*entity.Event{
+			{Timestamp: base, Kind: 1, CompetitorID: 1},
+			{Timestamp: base, Kind: 1, CompetitorID: 2},
+			{Timestamp: base, Kind: 2, CompetitorID: 1, AdditionalParam: "10:00:00.000"},
+			{Timestamp: base, Kind: 2, CompetitorID: 2, AdditionalParam: "10:01:00.000"},
+			{Timestamp: base, Kind: 4, CompetitorID: 1},
+			{Timestamp: base.Add(time.Minute), Kind: 4, CompetitorID: 2},
+			{Timestamp: base.Add(5 * time.Minute), Kind: 5, CompetitorID: 1, AdditionalParam: "1"},
+			{Timestamp: base.Add(5*time.Minute + 30*time.Second), Kind: 5, CompetitorID: 2, AdditionalParam: "1"},
+			{Timestamp: base.Add(6 * time.Minute), Kind: 7, CompetitorID: 2},
+			{Timestamp: base.Add(6*time.Minute + 10*time.Second), Kind: 7, CompetitorID: 1},
+			{Timestamp: base.Add(8 * time.Minute), Kind: 10, CompetitorID: 1},
+		}
+		for _, e := range events {
+			require.NoError(t, proc.Process(e))
+		}
+
+		require.Equal(t, []entity.TimingPoint{
+			{Kind: entity.PointRangeEntry, Index: 1},
+			{Kind: entity.PointRangeExit, Index: 1},
+			{Kind: entity.PointLapEnd, Index: 1},
+		}, proc.TimingPoints())
+
+		require.Equal(t, []entity.SplitRanking{
+			{Position: 1, CompetitorID: 2, Elapsed: 4*time.Minute + 30*time.Second},
+			{Position: 2, CompetitorID: 1, Elapsed: 5 * time.Minute, Gap: 30 * time.Second},
+		}, proc.GetSplits(entity.TimingPoint{Kind: entity.PointRangeEntry, Index: 1}))
+
+		require.Equal(t, []entity.SplitRanking{
+			{Position: 1, CompetitorID: 2, Elapsed: 5 * time.Minute},
+			{Position: 2, CompetitorID: 1, Elapsed: 6*time.Minute + 10*time.Second, Gap: 70 * time.Second},
+		}, proc.GetSplits(entity.TimingPoint{Kind: entity.PointRangeExit, Index: 1}))
+
+		require.Equal(t, []entity.SplitRanking{
+			{Position: 1, CompetitorID: 1, Elapsed: 8 * time.Minute, PositionChange: 1},
+		}, proc.GetSplits(entity.TimingPoint{Kind: entity.PointLapEnd, Index: 1}))
+	})
+	t.Run("standings tie test", func(t *testing.T) {
+		t.Parallel()
+		base, err := util.ConvertToTimestamp("10:00:00.000")
+		require.NoError(t, err)
+
+		var events []*entity.Event
+		for id, hits := range []int{3, 4, 4} {
+			id := int64(id + 1)
+			events = append(events,
+				&entity.Event{Timestamp: base, Kind: 1, CompetitorID: id},
+				&entity.Event{Timestamp: base, Kind: 2, CompetitorID: id, AdditionalParam: "10:00:00.000"},
+				&entity.Event{Timestamp: base, Kind: 4, CompetitorID: id},
+			)
+			for range hits {
+				events = append(events, &entity.Event{Timestamp: base, Kind: 6, CompetitorID: id})
+			}
+			events = append(events, &entity.Event{Timestamp: base.Add(10 * time.Minute), Kind: 10, CompetitorID: id})
+		}
+		events = append(events,
+			&entity.Event{Timestamp: base, Kind: 1, CompetitorID: 5},
+			&entity.Event{Timestamp: base, Kind: 1, CompetitorID: 4},
+		)
+
+		ranks := func(standings []entity.Standing) [][2]int64 {
+			var res [][2]int64
+			for _, s := range standings {
+				res = append(res, [2]int64{int64(s.Rank), s.Competitor.ID})
+			}
+			return res
+		}
+
+		proc := New(&config.Config{Laps: 1, StartDelta: "00:01:30"}, l)
+		for _, e := range events {
+			require.NoError(t, proc.Process(e))
+		}
+		require.Equal(t, [][2]int64{{1, 1}, {1, 2}, {1, 3}, {0, 4}, {0, 5}}, ranks(proc.GetStandings()))
+
+		proc = New(&config.Config{Laps: 1, StartDelta: "00:01:30", TieBreakers: []string{"misses"}}, l)
+		for _, e := range events {
+			require.NoError(t, proc.Process(e))
+		}
+		require.Equal(t, [][2]int64{{1, 2}, {1, 3}, {3, 1}, {0, 4}, {0, 5}}, ranks(proc.GetStandings()))
+
+		proc = New(&config.Config{Laps: 1, StartDelta: "00:01:30", TieBreakers: []string{"misses", "bib"}}, l)
+		for _, e := range events {
+			require.NoError(t, proc.Process(e))
+		}
+		require.Equal(t, [][2]int64{{1, 2}, {2, 3}, {3, 1}, {0, 4}, {0, 5}}, ranks(proc.GetStandings()))
+	})
+	t.Run("status test", func(t *testing.T) {
+		t.Parallel()
+		proc := New(&config.Config{Laps: 2, Start: "10:00:00.000", StartDelta: "00:01:30"}, l)
+		events := []*entity.Event{
+			{Timestamp: at(t, "09:30:00.000"), Kind: 1, CompetitorID: 1},
+			{Timestamp: at(t, "09:30:00.000"), Kind: 1, CompetitorID: 2},
+			{Timestamp: at(t, "09:30:00.000"), Kind: 1, CompetitorID: 3},
+			{Timestamp: at(t, "09:40:00.000"), Kind: 2, CompetitorID: 1, AdditionalParam: "10:00:00.000"},
+			{Timestamp: at(t, "09:40:00.000"), Kind: 2, CompetitorID: 2, AdditionalParam: "10:01:30.000"},
+			{Timestamp: at(t, "09:40:00.000"), Kind: 2, CompetitorID: 3, AdditionalParam: "10:03:00.000"},
+			{Timestamp: at(t, "10:00:01.000"), Kind: 1, CompetitorID: 4},
+			{Timestamp: at(t, "10:00:01.000"), Kind: 4, CompetitorID: 1},
+			{Timestamp: at(t, "10:06:00.000"), Kind: 4, CompetitorID: 3},
+			{Timestamp: at(t, "10:07:00.000"), Kind: 11, CompetitorID: 1, AdditionalParam: "Lost in the forest"},
+		}
+		for _, e := range events {
+			require.NoError(t, proc.Process(e))
+		}
+
+		// A competitor who already left the course cannot abandon it.
+		for _, id := range []int64{1, 3} {
+			err := proc.Process(&entity.Event{Timestamp: at(t, "10:08:00.000"), Kind: 11, CompetitorID: id, AdditionalParam: "Cramp"})
+			require.ErrorIs(t, err, entity.ErrCompetitorOffCourse)
+		}
+
+		statuses := make(map[int64]entity.Status)
+		for _, s := range proc.GetStandings() {
+			statuses[s.Competitor.ID] = s.Competitor.Status
+		}
+		require.Equal(t, map[int64]entity.Status{
+			1: entity.StatusDNF,
+			2: entity.StatusDNS,
+			3: entity.StatusDSQ,
+			4: entity.StatusDSQ,
+		}, statuses)
+
+		var outgoing []int64
+		for _, e := range proc.GetLog() {
+			if e.Kind > 31 {
+				outgoing = append(outgoing, e.Kind)
+			}
+		}
+		require.Equal(t, []int64{32, 34, 32, 35}, outgoing)
+	})
+	t.Run("time limit test", func(t *testing.T) {
+		t.Parallel()
+		cfg := &config.Config{
+			Laps:          2,
+			StartDelta:    "00:01:30",
+			CourseClosure: "10:30:00.000",
+			LapCutoffs:    []config.Cutoff{{Lap: 1, BehindLeader: "00:01:00"}},
+		}
+		proc := New(cfg, l)
+		var events []*entity.Event
+		for id, start := range []string{"10:00:00.000", "10:01:30.000", "10:03:00.000", "10:04:30.000"} {
+			events = append(events,
+				&entity.Event{Timestamp: at(t, "09:30:00.000"), Kind: 1, CompetitorID: int64(id + 1)},
+				&entity.Event{Timestamp: at(t, "09:30:00.000"), Kind: 2, CompetitorID: int64(id + 1), AdditionalParam: start},
+			)
+		}
+		events = append(events,
+			&entity.Event{Timestamp: at(t, "10:00:00.000"), Kind: 4, CompetitorID: 1},
+			&entity.Event{Timestamp: at(t, "10:01:30.000"), Kind: 4, CompetitorID: 2},
+			&entity.Event{Timestamp: at(t, "10:03:00.000"), Kind: 4, CompetitorID: 3},
+			&entity.Event{Timestamp: at(t, "10:04:30.000"), Kind: 4, CompetitorID: 4},
+			&entity.Event{Timestamp: at(t, "10:10:00.000"), Kind: 10, CompetitorID: 1},
+			&entity.Event{Timestamp: at(t, "10:12:31.000"), Kind: 10, CompetitorID: 2},
+			&entity.Event{Timestamp: at(t, "10:14:00.000"), Kind: 10, CompetitorID: 4},
+			&entity.Event{Timestamp: at(t, "10:20:00.000"), Kind: 10, CompetitorID: 1},
+			&entity.Event{Timestamp: at(t, "10:31:00.000"), Kind: 10, CompetitorID: 4},
+		)
+		for _, e := range events {
+			require.NoError(t, proc.Process(e))
+		}
+
+		statuses := make(map[int64]entity.Status)
+		for _, s := range proc.GetStandings() {
+			statuses[s.Competitor.ID] = s.Competitor.Status
+		}
+		require.Equal(t, map[int64]entity.Status{
+			1: entity.StatusFinished,
+			2: entity.StatusOTL,
+			3: entity.StatusOTL,
+			4: entity.StatusOTL,
+		}, statuses)
+		require.Equal(t, 1, proc.competitorList[2].LapCounter)
+
+		var pulled []int64
+		for _, e := range proc.GetLog() {
+			if e.Kind == 36 {
+				pulled = append(pulled, e.CompetitorID)
+			}
+		}
+		require.Equal(t, []int64{2, 3, 4}, pulled)
+	})
+	t.Run("lapped test", func(t *testing.T) {
+		t.Parallel()
+		var events []*entity.Event
+		for id := int64(1); id <= 3; id++ {
+			events = append(events,
+				&entity.Event{Timestamp: at(t, "09:30:00.000"), Kind: 1, CompetitorID: id},
+				&entity.Event{Timestamp: at(t, "09:30:00.000"), Kind: 2, CompetitorID: id, AdditionalParam: "10:00:00.000"},
+				&entity.Event{Timestamp: at(t, "10:00:00.000"), Kind: 4, CompetitorID: id},
+			)
+		}
+		events = append(events,
+			&entity.Event{Timestamp: at(t, "10:10:00.000"), Kind: 10, CompetitorID: 1},
+			&entity.Event{Timestamp: at(t, "10:12:00.000"), Kind: 10, CompetitorID: 3},
+			&entity.Event{Timestamp: at(t, "10:20:00.000"), Kind: 10, CompetitorID: 1},
+			&entity.Event{Timestamp: at(t, "10:21:00.000"), Kind: 10, CompetitorID: 2},
+			&entity.Event{Timestamp: at(t, "10:30:00.000"), Kind: 10, CompetitorID: 1},
+			&entity.Event{Timestamp: at(t, "10:31:00.000"), Kind: 10, CompetitorID: 3},
+		)
+
+		proc := New(&config.Config{Format: config.FormatMassStart, Laps: 3, StartDelta: "00:01:00"}, l)
+		for _, e := range events {
+			require.NoError(t, proc.Process(e))
+		}
+
+		standings := proc.GetStandings()
+		require.Len(t, standings, 3)
+		require.Equal(t, int64(1), standings[0].Competitor.ID)
+		require.Equal(t, entity.StatusFinished, standings[0].Competitor.Status)
+		require.Equal(t, entity.Standing{Rank: 2, LapsBehind: 1, LapCourseRanks: []int{2, 2, 0}, Competitor: proc.competitorList[3]}, standings[1])
+		require.Equal(t, entity.Standing{Rank: 3, LapsBehind: 2, LapCourseRanks: []int{3, 0, 0}, Competitor: proc.competitorList[2]}, standings[2])
+		require.Equal(t, entity.StatusLapped, standings[2].Competitor.Status)
+
+		proc = New(&config.Config{Format: config.FormatIndividual, Laps: 3, StartDelta: "00:01:00"}, l)
+		for _, e := range events {
+			require.NoError(t, proc.Process(e))
+		}
+		require.Equal(t, entity.StatusStarted, proc.competitorList[2].Status)
+		require.Equal(t, entity.StatusStarted, proc.competitorList[3].Status)
+	})
+	t.Run("jury decision test", func(t *testing.T) {
+		t.Parallel()
+		proc := New(&config.Config{Laps: 1, StartDelta: "00:01:30"}, l)
+		events := []*entity.Event{
+			{Timestamp: at(t, "09:30:00.000"), Kind: 1, CompetitorID: 1},
+			{Timestamp: at(t, "09:30:00.000"), Kind: 1, CompetitorID: 2},
+			{Timestamp: at(t, "09:30:00.000"), Kind: 1, CompetitorID: 3},
+			{Timestamp: at(t, "09:40:00.000"), Kind: 2, CompetitorID: 1, AdditionalParam: "10:00:00.000"},
+			{Timestamp: at(t, "09:40:00.000"), Kind: 2, CompetitorID: 2, AdditionalParam: "10:01:30.000"},
+			{Timestamp: at(t, "09:40:00.000"), Kind: 2, CompetitorID: 3, AdditionalParam: "10:03:00.000"},
+			{Timestamp: at(t, "10:00:00.000"), Kind: 4, CompetitorID: 1},
+			{Timestamp: at(t, "10:01:30.000"), Kind: 4, CompetitorID: 2},
+			{Timestamp: at(t, "10:05:00.000"), Kind: 4, CompetitorID: 3},
+			{Timestamp: at(t, "10:20:00.000"), Kind: 10, CompetitorID: 1},
+			{Timestamp: at(t, "10:21:00.000"), Kind: 10, CompetitorID: 2},
+		}
+		for _, e := range events {
+			require.NoError(t, proc.Process(e))
+		}
+		require.Error(t, proc.Process(&entity.Event{Timestamp: at(t, "10:22:00.000"), Kind: 10, CompetitorID: 3}))
+
+		ids := func() []int64 {
+			var res []int64
+			for _, s := range proc.GetStandings() {
+				if s.Rank > 0 {
+					res = append(res, s.Competitor.ID)
+				}
+			}
+			return res
+		}
+		require.Equal(t, []int64{2, 1}, ids())
+
+		require.NoError(t, proc.Process(&entity.Event{
+			Timestamp: at(t, "10:30:00.000"), Kind: entity.KindTimePenalty, CompetitorID: 2,
+			AdditionalParam: "00:02:00 shooting rule violation",
+		}))
+		require.Equal(t, []int64{1, 2}, ids())
+
+		require.NoError(t, proc.Process(&entity.Event{
+			Timestamp: at(t, "10:31:00.000"), Kind: entity.KindStatusChange, CompetitorID: 3,
+			AdditionalParam: "REINSTATE start interval missed by the timing system",
+		}))
+		require.Equal(t, []int64{3, 1, 2}, ids())
+
+		require.NoError(t, proc.Process(&entity.Event{
+			Timestamp: at(t, "10:32:00.000"), Kind: entity.KindCorrection, CompetitorID: 1,
+			AdditionalParam: "10 time=10:15:00.000 photo finish",
+		}))
+		require.Equal(t, []int64{1, 3, 2}, ids())
+
+		require.NoError(t, proc.Process(&entity.Event{
+			Timestamp: at(t, "10:33:00.000"), Kind: entity.KindStatusChange, CompetitorID: 1,
+			AdditionalParam: "DSQ unsporting behaviour",
+		}))
+		require.Equal(t, []int64{3, 2}, ids())
+		require.Equal(t, entity.RuleJury, proc.competitorList[1].Rule)
+
+		require.ErrorIs(t, proc.Process(&entity.Event{
+			Timestamp: at(t, "10:34:00.000"), Kind: entity.KindCorrection, CompetitorID: 2,
+			AdditionalParam: "10 time=10:15:00.000 wrong competitor",
+		}), entity.ErrEventNotFound)
+	})
+
+	t.Run("reinstate test", func(t *testing.T) {
+		t.Parallel()
+		proc := New(&config.Config{Laps: 1, LapLen: 1000, PenaltyLen: 100, FiringLines: 1, StartDelta: "00:01:30"}, l)
+		events := []*entity.Event{
+			{Timestamp: at(t, "09:30:00.000"), Kind: 1, CompetitorID: 1},
+			{Timestamp: at(t, "09:30:00.000"), Kind: 1, CompetitorID: 2},
+			{Timestamp: at(t, "09:30:00.000"), Kind: 1, CompetitorID: 3},
+			{Timestamp: at(t, "09:40:00.000"), Kind: 2, CompetitorID: 1, AdditionalParam: "10:00:00.000"},
+			{Timestamp: at(t, "09:40:00.000"), Kind: 2, CompetitorID: 2, AdditionalParam: "10:01:30.000"},
+			{Timestamp: at(t, "09:40:00.000"), Kind: 2, CompetitorID: 3, AdditionalParam: "10:03:00.000"},
+			{Timestamp: at(t, "10:00:00.000"), Kind: 4, CompetitorID: 1},
+			{Timestamp: at(t, "10:01:30.000"), Kind: 4, CompetitorID: 2},
+			{Timestamp: at(t, "10:06:00.000"), Kind: 4, CompetitorID: 3},
+			{Timestamp: at(t, "10:07:00.000"), Kind: 11, CompetitorID: 2, AdditionalParam: "fell"},
+			{Timestamp: at(t, "10:10:00.000"), Kind: 5, CompetitorID: 3, AdditionalParam: "1"},
+			{Timestamp: at(t, "10:11:00.000"), Kind: 7, CompetitorID: 3},
+			{Timestamp: at(t, "10:11:30.000"), Kind: 8, CompetitorID: 3},
+			{Timestamp: at(t, "10:20:00.000"), Kind: 10, CompetitorID: 1},
+			{Timestamp: at(t, "10:22:00.000"), Kind: 10, CompetitorID: 2},
+			{Timestamp: at(t, "10:25:00.000"), Kind: 10, CompetitorID: 3},
+		}
+		for _, e := range events {
+			_ = proc.Process(e)
+		}
+		jury := func(id int64, param string) {
+			require.NoError(t, proc.Process(&entity.Event{
+				Timestamp: at(t, "10:30:00.000"), Kind: entity.KindStatusChange, CompetitorID: id, AdditionalParam: param,
+			}))
+		}
+		status := func(id int64) entity.Status {
+			return proc.competitorList[id].Status
+		}
+		require.Equal(t, entity.StatusFinished, status(1))
+		require.Equal(t, entity.StatusDNF, status(2))
+		require.Equal(t, entity.StatusDSQ, status(3))
+		require.Equal(t, entity.RuleLateStart, proc.competitorList[3].Rule)
+
+		jury(1, "DSQ unsporting behaviour")
+		require.Equal(t, entity.StatusDSQ, status(1))
+		jury(1, "REINSTATE protest upheld")
+		require.Equal(t, entity.StatusFinished, status(1))
+
+		jury(2, "REINSTATE fall caused by a spectator")
+		require.Equal(t, entity.StatusFinished, status(2))
+		require.Equal(t, 20*time.Minute+30*time.Second, proc.competitorList[2].TotalTime())
+
+		// The first reinstatement only waives the late start, the penalty
+		// loop rule still applies.
+		jury(3, "REINSTATE start gate malfunction")
+		require.Equal(t, entity.StatusDSQ, status(3))
+		require.Equal(t, entity.RulePenaltyLoop, proc.competitorList[3].Rule)
+		jury(3, "REINSTATE penalty loop gate malfunction")
+		require.Equal(t, entity.StatusFinished, status(3))
+
+		jury(1, "REINSTATE nothing to overturn")
+		require.Equal(t, entity.StatusFinished, status(1))
+		require.Len(t, proc.GetResult(), 3)
+	})
+
+	t.Run("rebuild rejection test", func(t *testing.T) {
+		t.Parallel()
+		core, logs := observer.New(zap.WarnLevel)
+		proc := New(&config.Config{Laps: 1, StartDelta: "00:01:30"}, zap.New(core))
+		events := []*entity.Event{
+			{Timestamp: at(t, "09:30:00.000"), Kind: 1, CompetitorID: 1},
+			{Timestamp: at(t, "09:40:00.000"), Kind: 2, CompetitorID: 1, AdditionalParam: "10:00:00.000"},
+			{Timestamp: at(t, "10:00:00.000"), Kind: 4, CompetitorID: 1},
+			{Timestamp: at(t, "10:20:00.000"), Kind: 10, CompetitorID: 1},
+		}
+		for _, e := range events {
+			require.NoError(t, proc.Process(e))
+		}
+		require.Error(t, proc.Process(&entity.Event{Timestamp: at(t, "10:21:00.000"), Kind: 10, CompetitorID: 2}))
+
+		require.NoError(t, proc.Process(&entity.Event{
+			Timestamp: at(t, "10:30:00.000"), Kind: entity.KindCorrection, CompetitorID: 1,
+			AdditionalParam: "3 time=10:05:00.000 start gate clock",
+		}))
+		require.Equal(t, entity.StatusDSQ, proc.competitorList[1].Status)
+
+		rejected := logs.FilterMessage("event rejected after the race was recomputed").All()
+		require.Len(t, rejected, 1)
+		require.Equal(t, int64(4), rejected[0].ContextMap()["line"])
+	})
+
+	t.Run("listener test", func(t *testing.T) {
+		t.Parallel()
+		proc := New(&config.Config{Laps: 1, StartDelta: "00:01:30"}, l)
+		var kinds [][]int64
+		var errs []error
+		proc.Subscribe(func(_ *entity.Event, logged []*entity.Event, err error) {
+			var res []int64
+			for _, e := range logged {
+				res = append(res, e.Kind)
+			}
+			kinds = append(kinds, res)
+			errs = append(errs, err)
+		})
+
+		events := []*entity.Event{
+			{Timestamp: at(t, "09:30:00.000"), Kind: 1, CompetitorID: 1},
+			{Timestamp: at(t, "09:40:00.000"), Kind: 2, CompetitorID: 1, AdditionalParam: "10:00:00.000"},
+			{Timestamp: at(t, "10:00:00.000"), Kind: 4, CompetitorID: 1},
+			{Timestamp: at(t, "10:20:00.000"), Kind: 10, CompetitorID: 1},
+			{Timestamp: at(t, "10:21:00.000"), Kind: 10, CompetitorID: 2},
+			{Timestamp: at(t, "10:30:00.000"), Kind: entity.KindStatusChange, CompetitorID: 1, AdditionalParam: "DSQ unsporting behaviour"},
+		}
+		for _, e := range events {
+			_ = proc.Process(e)
+		}
+
+		require.Equal(t, [][]int64{{1}, {2}, {4}, {10, 33}, nil, {entity.KindStatusChange, 32}}, kinds)
+		require.ErrorIs(t, errs[4], entity.ErrCompetitorNotFound)
+	})
+
+	t.Run("retract test", func(t *testing.T) {
+		t.Parallel()
+		proc := New(&config.Config{Laps: 1, StartDelta: "00:01:30"}, l)
+		events := []*entity.Event{
+			{Timestamp: at(t, "09:30:00.000"), Kind: 1, CompetitorID: 1},
+			{Timestamp: at(t, "09:30:00.000"), Kind: 1, CompetitorID: 2},
+			{Timestamp: at(t, "09:40:00.000"), Kind: 2, CompetitorID: 1, AdditionalParam: "10:00:00.000"},
+			{Timestamp: at(t, "09:40:00.000"), Kind: 2, CompetitorID: 2, AdditionalParam: "10:01:30.000"},
+			{Timestamp: at(t, "10:00:00.000"), Kind: 4, CompetitorID: 1},
+			{Timestamp: at(t, "10:01:30.000"), Kind: 4, CompetitorID: 2},
+			{Timestamp: at(t, "10:20:00.000"), Kind: 10, CompetitorID: 1},
+			{Timestamp: at(t, "10:21:00.000"), Kind: 10, CompetitorID: 2},
+		}
+		for _, e := range events {
+			require.NoError(t, proc.Process(e))
+		}
+
+		var edits []*entity.Event
+		proc.Subscribe(func(event *entity.Event, logged []*entity.Event, _ error) {
+			if event.Kind == entity.KindEdit {
+				require.Equal(t, []*entity.Event{event}, logged)
+				edits = append(edits, event)
+			}
+		})
+
+		changes, err := proc.Replace(7, &entity.Event{Timestamp: at(t, "10:15:00.000"), Kind: 10, CompetitorID: 1}, "wrong lap time")
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		require.Equal(t, int64(1), proc.GetStandings()[0].Competitor.ID)
+		require.Equal(t, 7, proc.input[6].Line)
+
+		changes, err = proc.Retract(7, "")
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		require.Equal(t, entity.StatusStarted, proc.competitorList[1].Status)
+
+		_, err = proc.Retract(7, "")
+		require.ErrorIs(t, err, entity.ErrEventNotFound)
+
+		require.Len(t, edits, 2)
+		require.Equal(t, int64(entity.KindEdit), edits[0].Kind)
+		require.Equal(t, 7, edits[0].Line)
+		require.Equal(t, "The event on line 7 was replaced: wrong lap time", edits[0].Comment)
+		require.Equal(t, at(t, "10:20:00.000"), edits[0].Edit.Old.Timestamp)
+		require.Equal(t, at(t, "10:15:00.000"), edits[0].Edit.New.Timestamp)
+		require.Equal(t, "The event on line 7 was retracted", edits[1].Comment)
+		require.Nil(t, edits[1].Edit.New)
+
+		e := &entity.Event{Timestamp: at(t, "10:22:00.000"), Kind: 10, CompetitorID: 1}
+		require.NoError(t, proc.Process(e))
+		require.Equal(t, 9, e.Line)
+	})
 }
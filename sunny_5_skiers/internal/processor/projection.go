@@ -0,0 +1,162 @@
+package processor
+
+import (
+	"biathlon/internal/entity"
+	"time"
+)
+
+// form sums the performance of a competitor up to the end of the last
+// completed lap: the ski time without range and penalty loop time, the
+// range visits and the penalty loop time per missed target.
+type form struct {
+	skiTime     time.Duration
+	distance    int
+	rangeTime   time.Duration
+	visits      int
+	penaltyTime time.Duration
+	misses      int
+}
+
+func (f *form) add(other form) {
+	f.skiTime += other.skiTime
+	f.distance += other.distance
+	f.rangeTime += other.rangeTime
+	f.visits += other.visits
+	f.penaltyTime += other.penaltyTime
+	f.misses += other.misses
+}
+
+func (p *processorImpl) formOf(c *entity.Competitior) form {
+	var res form
+	if c.LapCounter == 0 {
+		return res
+	}
+	lastLapEnd := c.MainLapsData[c.LapCounter-1].FinishLap
+
+	for _, l := range c.MainLapsData[:c.LapCounter] {
+		if d, ok := l.Duration(); ok {
+			res.skiTime += d
+			res.distance += l.Size
+		}
+	}
+
+	for _, r := range c.RangeData {
+		if r.Exit.IsZero() || r.Exit.After(lastLapEnd) {
+			continue
+		}
+		res.rangeTime += r.Exit.Sub(r.Enter)
+		res.visits++
+		res.misses += entity.TargetsPerRange - len(r.Hits)
+	}
+
+	for _, l := range c.PenaltyLapData {
+		if d, ok := l.Duration(); ok && !l.FinishLap.After(lastLapEnd) {
+			res.penaltyTime += d
+		}
+	}
+
+	res.skiTime -= res.rangeTime + res.penaltyTime
+	return res
+}
+
+// fieldForm sums the form of all competitors who completed a lap.
+func (p *processorImpl) fieldForm() form {
+	var res form
+	for _, c := range p.competitors {
+		res.add(p.formOf(c))
+	}
+	return res
+}
+
+// project estimates the total time of the competitor from the ski pace of
+// the completed laps, the remaining distance and range visits, and the
+// range and penalty loop times so far. The field averages are used until
+// the competitor completes a lap.
+func (p *processorImpl) project(c *entity.Competitior, now time.Time) {
+	own := p.formOf(c)
+	field := p.fieldForm()
+
+	base := own
+	if base.distance == 0 {
+		base = field
+	}
+	if base.distance == 0 || base.skiTime <= 0 {
+		return
+	}
+	pace := float64(base.skiTime) / float64(base.distance)
+
+	shooting := own
+	if shooting.visits == 0 {
+		shooting = field
+	}
+
+	var rangeTime time.Duration
+	var missRate float64
+	if shooting.visits > 0 {
+		rangeTime = shooting.rangeTime / time.Duration(shooting.visits)
+		missRate = float64(shooting.misses) / float64(shooting.visits*entity.TargetsPerRange)
+	}
+
+	// The penalty time per miss falls back to skiing the penalty loop at
+	// the competitor pace if no penalty loop was completed yet.
+	perMiss := time.Duration(float64(p.cfg.PenaltyLen) * pace)
+	if own.misses > 0 && own.penaltyTime > 0 {
+		perMiss = own.penaltyTime / time.Duration(own.misses)
+	} else if field.misses > 0 && field.penaltyTime > 0 {
+		perMiss = field.penaltyTime / time.Duration(field.misses)
+	}
+
+	remaining := time.Duration(float64((p.cfg.Laps-c.LapCounter)*p.cfg.LapLen) * pace)
+
+	visits := 0
+	if p.cfg.FiringLines > 0 {
+		visits = p.cfg.Laps - len(c.RangeData)
+	}
+
+	lap := c.MainLapsData[len(c.MainLapsData)-1]
+	if n := len(c.RangeData); n > 0 && !c.RangeData[n-1].Exit.IsZero() && c.RangeData[n-1].Exit.After(lap.StartLap) {
+		// On the range exit the part of the lap skied before the range is
+		// already done and the penalty loop of the visit is still ahead.
+		current := c.RangeData[n-1]
+		skied := current.Enter.Sub(lap.StartLap)
+		remaining -= min(skied, time.Duration(float64(p.cfg.LapLen)*pace))
+		remaining += time.Duration(entity.TargetsPerRange-len(current.Hits)) * perMiss
+	}
+
+	remaining += time.Duration(visits) * (rangeTime + time.Duration(missRate*entity.TargetsPerRange*float64(perMiss)))
+
+	c.Projection = &entity.Projection{
+		TotalTime: now.Sub(c.ScheduledStartTime) + remaining + c.TimePenalty,
+		At:        now,
+	}
+}
+
+// projectRanks ranks the projected total times of the competitors on the
+// course among the total times of the finishers.
+func projectRanks(standings []entity.Standing) {
+	var times []time.Duration
+	for _, s := range standings {
+		c := s.Competitor
+		switch {
+		case c.Status == entity.StatusFinished:
+			times = append(times, c.TotalTime())
+		case c.Status == entity.StatusStarted && c.Projection != nil:
+			times = append(times, c.Projection.TotalTime)
+		}
+	}
+
+	for i, s := range standings {
+		c := s.Competitor
+		if c.Status != entity.StatusStarted || c.Projection == nil {
+			continue
+		}
+
+		rank := 1
+		for _, t := range times {
+			if t < c.Projection.TotalTime {
+				rank++
+			}
+		}
+		standings[i].ProjectedRank = rank
+	}
+}
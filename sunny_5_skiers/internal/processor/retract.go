@@ -0,0 +1,109 @@
+package processor
+
+import (
+	"biathlon/internal/entity"
+	"slices"
+
+	"go.uber.org/zap"
+)
+
+func (p *processorImpl) inputIndex(line int) int {
+	return slices.IndexFunc(p.input, func(e *entity.Event) bool {
+		return e.Line == line
+	})
+}
+
+// Retract removes the incoming event on the line from the event stream,
+// recomputes the race and returns the results that changed. The reason is
+// passed to the listeners with the edit.
+func (p *processorImpl) Retract(line int, reason string) ([]entity.ResultChange, error) {
+	i := p.inputIndex(line)
+	if i < 0 {
+		err := entity.ErrEventNotFound
+		p.logger.Error("failed to find retracted event", zap.Int("line", line), zap.Error(err))
+		return nil, err
+	}
+
+	before, log := p.GetStandings(), p.events
+	old := p.input[i]
+	p.input = slices.Delete(p.input, i, i+1)
+	p.rebuild()
+	p.edited(line, &entity.EventEdit{Old: old, Reason: reason}, log)
+	return entity.DiffStandings(before, p.GetStandings()), nil
+}
+
+// Replace puts the event in place of the incoming event on the line,
+// recomputes the race and returns the results that changed.
+func (p *processorImpl) Replace(line int, event *entity.Event, reason string) ([]entity.ResultChange, error) {
+	i := p.inputIndex(line)
+	if i < 0 {
+		err := entity.ErrEventNotFound
+		p.logger.Error("failed to find replaced event", zap.Int("line", line), zap.Error(err))
+		return nil, err
+	}
+
+	if entity.IsJuryKind(event.Kind) {
+		_, err := entity.ParseJuryDecision(event)
+		if err != nil {
+			p.logger.Error("failed to parse jury decision", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	before, log := p.GetStandings(), p.events
+	old := p.input[i]
+	event.Line = line
+	p.input[i] = event
+	p.rebuild()
+	p.edited(line, &entity.EventEdit{Old: old, New: event, Reason: reason}, log)
+	return entity.DiffStandings(before, p.GetStandings()), nil
+}
+
+// Insert adds the event to the event stream before the first event with
+// a later timestamp, recomputes the race and returns the results that
+// changed. The event is numbered after the last line of the stream.
+func (p *processorImpl) Insert(event *entity.Event, reason string) ([]entity.ResultChange, error) {
+	if entity.IsJuryKind(event.Kind) {
+		_, err := entity.ParseJuryDecision(event)
+		if err != nil {
+			p.logger.Error("failed to parse jury decision", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	i := slices.IndexFunc(p.input, func(e *entity.Event) bool {
+		return e.Timestamp.After(event.Timestamp)
+	})
+	if i < 0 {
+		i = len(p.input)
+	}
+
+	before, log := p.GetStandings(), p.events
+	p.lastLine++
+	event.Line = p.lastLine
+	p.input = slices.Insert(p.input, i, event)
+	p.rebuild()
+	p.edited(event.Line, &entity.EventEdit{New: event, Reason: reason}, log)
+	return entity.DiffStandings(before, p.GetStandings()), nil
+}
+
+// edited passes the log entry of the edit to the listeners once the race
+// is recomputed, the entry is not a part of the log. The recomputation of
+// the log before the edit follows it.
+func (p *processorImpl) edited(line int, edit *entity.EventEdit, log []*entity.Event) {
+	event := entity.EditEvent(line, edit)
+	p.notify(event, []*entity.Event{event}, nil)
+	p.recomputed(event, log, p.events)
+}
+
+// recomputed passes the log entry of the recomputation with the added log
+// entries to the listeners, the removed entries are set on the entry.
+func (p *processorImpl) recomputed(cause *entity.Event, before, after []*entity.Event) {
+	change := entity.DiffLog(before, after)
+	if len(change.Removed) == 0 && len(change.Added) == 0 {
+		return
+	}
+
+	event := entity.RecomputeEvent(cause, change)
+	p.notify(event, append([]*entity.Event{event}, change.Added...), nil)
+}
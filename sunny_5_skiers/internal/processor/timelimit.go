@@ -0,0 +1,191 @@
+package processor
+
+import (
+	"biathlon/internal/entity"
+	"biathlon/internal/util"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type cutoff struct {
+	lap          int
+	clock        time.Time
+	behindLeader time.Duration
+}
+
+type timeLimits struct {
+	courseClosure time.Time
+	cutoffs       []cutoff
+}
+
+// parseTimeLimits converts the configured time limits, config.Load has
+// already rejected the values that do not parse.
+func (p *processorImpl) parseTimeLimits() timeLimits {
+	var res timeLimits
+	var err error
+
+	if p.cfg.CourseClosure != "" {
+		res.courseClosure, err = util.ConvertToTimestamp(p.cfg.CourseClosure)
+		if err != nil {
+			p.logger.Error("failed to convert course closure to timestamp", zap.Error(err))
+		}
+	}
+
+	for _, c := range p.cfg.LapCutoffs {
+		parsed := cutoff{lap: c.Lap}
+		if c.Clock != "" {
+			parsed.clock, err = util.ConvertToTimestamp(c.Clock)
+			if err != nil {
+				p.logger.Error("failed to convert lap cut-off to timestamp", zap.Int("lap", c.Lap), zap.Error(err))
+				continue
+			}
+		}
+		if c.BehindLeader != "" {
+			parsed.behindLeader, err = util.ConvertToDuration(c.BehindLeader)
+			if err != nil {
+				p.logger.Error("failed to convert lap cut-off to duration", zap.Int("lap", c.Lap), zap.Error(err))
+				continue
+			}
+		}
+		res.cutoffs = append(res.cutoffs, parsed)
+	}
+
+	return res
+}
+
+// leaderElapsed returns the best elapsed time at the end of the lap.
+func (p *processorImpl) leaderElapsed(lap int) (time.Duration, bool) {
+	splits := p.splits[entity.TimingPoint{Kind: entity.PointLapEnd, Index: lap}]
+	if len(splits) == 0 {
+		return 0, false
+	}
+
+	best := splits[0].Elapsed
+	for _, s := range splits[1:] {
+		best = min(best, s.Elapsed)
+	}
+	return best, true
+}
+
+// timeLimit is a time limit of the competitor and the reason they are
+// pulled out once it is exceeded.
+type timeLimit struct {
+	at     time.Time
+	reason string
+}
+
+// timeLimitsOf returns the time limits that apply to the competitor in
+// the order they are checked. Cut-offs behind the leader apply once the
+// leader finished the lap.
+func (p *processorImpl) timeLimitsOf(c *entity.Competitior) []timeLimit {
+	var res []timeLimit
+	if !p.limits.courseClosure.IsZero() {
+		res = append(res, timeLimit{p.limits.courseClosure, "course closed at " + util.FormatTimestamp(p.limits.courseClosure)})
+	}
+
+	for _, cut := range p.limits.cutoffs {
+		if c.LapCounter >= cut.lap {
+			continue
+		}
+
+		if !cut.clock.IsZero() {
+			res = append(res, timeLimit{cut.clock, fmt.Sprintf("lap %d cut-off at %s", cut.lap, util.FormatTimestamp(cut.clock))})
+		}
+
+		if cut.behindLeader == 0 {
+			continue
+		}
+
+		leader, ok := p.leaderElapsed(cut.lap)
+		if ok {
+			res = append(res, timeLimit{
+				c.ScheduledStartTime.Add(leader + cut.behindLeader),
+				fmt.Sprintf("lap %d cut-off %s behind the leader", cut.lap, util.FormatDuration(cut.behindLeader)),
+			})
+		}
+	}
+	return res
+}
+
+// exceededTimeLimit returns the reason the competitor has to be pulled
+// out at the given time or an empty string if they are within the limits.
+func (p *processorImpl) exceededTimeLimit(c *entity.Competitior, now time.Time) string {
+	for _, l := range p.timeLimitsOf(c) {
+		if now.After(l.at) {
+			return l.reason
+		}
+	}
+	return ""
+}
+
+// pullOut marks the competitor as over the time limit. A competitor who
+// finished with the event itself is pulled out as well.
+func (p *processorImpl) pullOut(c *entity.Competitior, reason string, event *entity.Event) {
+	if c.Status != entity.StatusStarted && c.Status != entity.StatusFinished || p.jury.waived(c.ID, ruleTimeLimit) {
+		return
+	}
+
+	c.SetStatus(entity.StatusOTL, reason, event)
+	p.appendOutgoing(entity.OverTimeLimitEvent(c.ID, event.Timestamp, reason), event)
+}
+
+// checkTimeLimits pulls out competitors on the course who exceeded a time
+// limit by the time of the event. The competitor of the event is checked
+// by Process, so their lap end is recorded before pulling out.
+func (p *processorImpl) checkTimeLimits(event *entity.Event) {
+	for _, c := range p.sortedCompetitors() {
+		if c.ID == event.CompetitorID || c.Status != entity.StatusStarted {
+			continue
+		}
+
+		if reason := p.exceededTimeLimit(c, event.Timestamp); reason != "" {
+			p.pullOut(c, reason, event)
+		}
+	}
+}
+
+// Close ends the event stream. No later event can pull out the
+// competitors still on the course, so they are pulled out at their first
+// time limit and the entries are passed to the listeners.
+func (p *processorImpl) Close() {
+	p.closed = true
+	p.applied = len(p.events)
+	p.closeCourse()
+	for _, e := range p.events[p.applied:] {
+		p.notify(e, []*entity.Event{e}, nil)
+	}
+}
+
+// closeCourse pulls out the competitors on the course in the order their
+// time limits expire.
+func (p *processorImpl) closeCourse() {
+	type expiry struct {
+		competitor *entity.Competitior
+		limit      timeLimit
+	}
+
+	var expired []expiry
+	for _, c := range p.sortedCompetitors() {
+		if c.Status != entity.StatusStarted {
+			continue
+		}
+
+		limits := p.timeLimitsOf(c)
+		if len(limits) == 0 {
+			continue
+		}
+		expired = append(expired, expiry{c, slices.MinFunc(limits, func(a, b timeLimit) int {
+			return a.at.Compare(b.at)
+		})})
+	}
+	slices.SortStableFunc(expired, func(a, b expiry) int {
+		return a.limit.at.Compare(b.limit.at)
+	})
+
+	for _, e := range expired {
+		p.pullOut(e.competitor, e.limit.reason, &entity.Event{Timestamp: e.limit.at, CompetitorID: e.competitor.ID})
+	}
+}
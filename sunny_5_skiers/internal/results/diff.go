@@ -0,0 +1,210 @@
+package results
+
+import (
+	"biathlon/internal/util"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Delta is a changed value of a result field.
+type Delta struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+	// Delta is the signed difference of time values, empty for others.
+	Delta string `json:"delta,omitempty"`
+}
+
+type LapDelta struct {
+	Lap int `json:"lap"`
+	Delta
+}
+
+// Change is the difference of a competitor result between two result
+// sets. Before or After is nil if the competitor is missing from them.
+type Change struct {
+	CompetitorID int64 `json:"competitor"`
+	Before       *Row  `json:"before,omitempty"`
+	After        *Row  `json:"after,omitempty"`
+	// RankChange is the number of places gained, negative if lost.
+	RankChange int `json:"rankChange,omitempty"`
+	// TimeDelta is the signed total time difference of finishers.
+	TimeDelta string     `json:"timeDelta,omitempty"`
+	Status    *Delta     `json:"status,omitempty"`
+	TotalTime *Delta     `json:"totalTime,omitempty"`
+	Laps      []LapDelta `json:"laps,omitempty"`
+	Shooting  *Delta     `json:"shooting,omitempty"`
+	// Fields are the JSON names of the changed row fields.
+	Fields []string `json:"fields,omitempty"`
+}
+
+// Diff compares the result rows by competitor and returns the changed
+// ones ordered by competitor ID.
+func Diff(before, after []Row) []Change {
+	rows := func(rows []Row) map[int64]*Row {
+		res := make(map[int64]*Row, len(rows))
+		for i := range rows {
+			res[rows[i].CompetitorID] = &rows[i]
+		}
+		return res
+	}
+	a, b := rows(before), rows(after)
+
+	ids := make([]int64, 0, len(a)+len(b))
+	for id := range a {
+		ids = append(ids, id)
+	}
+	for id := range b {
+		if _, ok := a[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	var res []Change
+	for _, id := range ids {
+		change := Change{CompetitorID: id, Before: a[id], After: b[id]}
+		if change.Before != nil && change.After != nil {
+			change.Fields = changedFields(*change.Before, *change.After)
+			if len(change.Fields) == 0 {
+				continue
+			}
+			change.compare()
+		}
+		res = append(res, change)
+	}
+	return res
+}
+
+func (c *Change) compare() {
+	x, y := c.Before, c.After
+	if x.Rank != 0 && y.Rank != 0 {
+		c.RankChange = x.Rank - y.Rank
+	}
+
+	if x.Status != y.Status {
+		c.Status = &Delta{Before: x.Status, After: y.Status}
+	}
+
+	if x.TotalTime != y.TotalTime {
+		c.TotalTime = timeDelta(x.TotalTime, y.TotalTime)
+		c.TimeDelta = c.TotalTime.Delta
+	}
+
+	for i := range max(len(x.Laps), len(y.Laps)) {
+		var lx, ly Lap
+		if i < len(x.Laps) {
+			lx = x.Laps[i]
+		}
+		if i < len(y.Laps) {
+			ly = y.Laps[i]
+		}
+
+		if lx.Time != ly.Time {
+			c.Laps = append(c.Laps, LapDelta{Lap: i + 1, Delta: *timeDelta(lx.Time, ly.Time)})
+		}
+	}
+
+	if x.Hits != y.Hits || x.Shots != y.Shots {
+		c.Shooting = &Delta{Before: shooting(x), After: shooting(y)}
+	}
+}
+
+func timeDelta(before, after string) *Delta {
+	res := &Delta{Before: before, After: after}
+	x, errBefore := util.ParseDuration(before)
+	y, errAfter := util.ParseDuration(after)
+	if errBefore == nil && errAfter == nil {
+		res.Delta = util.FormatDelta(y - x)
+	}
+	return res
+}
+
+func shooting(r *Row) string {
+	return fmt.Sprintf("%d/%d", r.Hits, r.Shots)
+}
+
+func changedFields(a, b Row) []string {
+	var res []string
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := range va.NumField() {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			name, _, _ := strings.Cut(va.Type().Field(i).Tag.Get("json"), ",")
+			res = append(res, name)
+		}
+	}
+	return res
+}
+
+// WriteDiff writes the changes as text, a summary line per competitor
+// followed by the changed status, total time, lap times and shooting.
+func WriteDiff(w io.Writer, changes []Change) error {
+	for _, c := range changes {
+		lines := []string{fmt.Sprintf("competitor(%d): %s -> %s%s",
+			c.CompetitorID, summary(c.Before), summary(c.After), changeNote(c))}
+
+		if c.Status != nil {
+			lines = append(lines, "  status: "+c.Status.String())
+		}
+		if c.TotalTime != nil {
+			lines = append(lines, "  total: "+c.TotalTime.String())
+		}
+		for _, l := range c.Laps {
+			lines = append(lines, fmt.Sprintf("  lap %d: %s", l.Lap, l.Delta.String()))
+		}
+		if c.Shooting != nil {
+			lines = append(lines, "  shooting: "+c.Shooting.String())
+		}
+
+		_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d Delta) String() string {
+	res := OrNone(d.Before) + " -> " + OrNone(d.After)
+	if d.Delta != "" {
+		res += " (" + d.Delta + ")"
+	}
+	return res
+}
+
+// OrNone returns the result or "none" if it is empty.
+func OrNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+func summary(r *Row) string {
+	switch {
+	case r == nil:
+		return "none"
+	case r.Rank == 0:
+		return "- " + r.Status
+	case r.TotalTime == "":
+		return fmt.Sprintf("%d %s", r.Rank, r.Status)
+	default:
+		return fmt.Sprintf("%d %s", r.Rank, r.TotalTime)
+	}
+}
+
+func changeNote(c Change) string {
+	switch {
+	case c.RankChange != 0 && c.TimeDelta != "":
+		return fmt.Sprintf(" (%+d, %s)", c.RankChange, c.TimeDelta)
+	case c.RankChange != 0:
+		return fmt.Sprintf(" (%+d)", c.RankChange)
+	case c.TimeDelta != "":
+		return fmt.Sprintf(" (%s)", c.TimeDelta)
+	case len(c.Fields) > 0:
+		return fmt.Sprintf(" (%s)", strings.Join(c.Fields, ", "))
+	}
+	return ""
+}
@@ -0,0 +1,270 @@
+package results
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+// Sheet is a results artifact. An official sheet is sealed with the hash of
+// its content and optionally signed, every change creates a new version
+// referencing the hash of the version it supersedes.
+type Sheet struct {
+	Version         int    `json:"version,omitempty"`
+	State           State  `json:"state"`
+	Clock           string `json:"clock"`
+	ProtestDeadline string `json:"protestDeadline,omitempty"`
+	Reason          string `json:"reason,omitempty"`
+	Supersedes      string `json:"supersedes,omitempty"`
+	Rows            []Row  `json:"results"`
+	AuditHead       string `json:"auditHead,omitempty"`
+	Hash            string `json:"hash,omitempty"`
+	Signature       string `json:"signature,omitempty"`
+}
+
+func (s *Sheet) digest() (string, error) {
+	content := *s
+	content.Hash = ""
+	content.Signature = ""
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func sign(key []byte, hash string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(hash))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Seal sets the content hash of the sheet and signs it with the key
+// if it is not empty.
+func (s *Sheet) Seal(key []byte) error {
+	hash, err := s.digest()
+	if err != nil {
+		return err
+	}
+
+	s.Hash = hash
+	s.Signature = ""
+	if len(key) > 0 {
+		s.Signature = sign(key, hash)
+	}
+	return nil
+}
+
+// Verify checks that the sheet was not changed after it was sealed. The
+// hash alone can be recomputed after a change, so a signed sheet can only
+// be verified with the key.
+func (s *Sheet) Verify(key []byte) error {
+	hash, err := s.digest()
+	if err != nil {
+		return err
+	}
+
+	if hash != s.Hash {
+		return ErrHashMismatch
+	}
+
+	if len(key) == 0 {
+		if s.Signature != "" {
+			return ErrSigningKeyRequired
+		}
+		return nil
+	}
+
+	if s.Signature == "" || !hmac.Equal([]byte(s.Signature), []byte(sign(key, hash))) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+func (s *Sheet) sameResults(other *Sheet) (bool, error) {
+	a, err := json.Marshal(s.Rows)
+	if err != nil {
+		return false, err
+	}
+
+	b, err := json.Marshal(other.Rows)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(a, b), nil
+}
+
+var versionPattern = regexp.MustCompile(`^official\.v(\d+)\.json$`)
+
+func ReadSheet(path string) (*Sheet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var res Sheet
+	err = json.Unmarshal(data, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ReadRows reads the result rows of a results file, either a sheet or
+// a bare JSON array of rows.
+func ReadRows(path string) ([]Row, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Row
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		err = json.Unmarshal(data, &res)
+		return res, err
+	}
+
+	var sheet Sheet
+	err = json.Unmarshal(data, &sheet)
+	if err != nil {
+		return nil, err
+	}
+	return sheet.Rows, nil
+}
+
+// Latest returns the latest official version in the directory,
+// nil if the results were never finalized.
+func Latest(dir string) (*Sheet, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	latest := 0
+	for _, e := range entries {
+		m := versionPattern.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(m[1])
+		latest = max(latest, version)
+	}
+
+	if latest == 0 {
+		return nil, nil
+	}
+	return ReadSheet(filepath.Join(dir, versionName(latest)))
+}
+
+func versionName(version int) string {
+	return fmt.Sprintf("official.v%d.json", version)
+}
+
+// Finalize writes the sheet as the next official version to the directory.
+// If the results do not differ from the latest version it is returned
+// unchanged, a revision of already official results requires a reason.
+// Once a version was signed, the next ones require the key.
+func Finalize(dir string, sheet *Sheet, key []byte) (*Sheet, string, error) {
+	latest, err := Latest(dir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	sheet.State = StateOfficial
+	sheet.Version = 1
+	if latest != nil {
+		if len(key) == 0 {
+			err = checkUnsigned(dir, latest.Version)
+			if err != nil {
+				return nil, "", err
+			}
+		}
+
+		err = latest.Verify(key)
+		if err != nil {
+			return nil, "", fmt.Errorf("version %d: %w", latest.Version, err)
+		}
+
+		same, err := latest.sameResults(sheet)
+		if err != nil {
+			return nil, "", err
+		}
+		if same {
+			return latest, filepath.Join(dir, versionName(latest.Version)), nil
+		}
+
+		if sheet.Reason == "" {
+			return nil, "", ErrRevisionReason
+		}
+		sheet.Version = latest.Version + 1
+		sheet.Supersedes = latest.Hash
+	}
+
+	err = sheet.Seal(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := json.MarshalIndent(sheet, "", "    ")
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, "", err
+	}
+
+	path := filepath.Join(dir, versionName(sheet.Version))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o444)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(data, '\n'))
+	if err != nil {
+		return nil, "", err
+	}
+	return sheet, path, nil
+}
+
+// checkUnsigned fails if any version up to latest is signed, the
+// signature of the latest one might have been removed.
+func checkUnsigned(dir string, latest int) error {
+	for version := 1; version <= latest; version++ {
+		sheet, err := ReadSheet(filepath.Join(dir, versionName(version)))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if sheet.Signature != "" {
+			return fmt.Errorf("version %d: %w", version, ErrSigningKeyRequired)
+		}
+	}
+	return nil
+}
+
+var (
+	ErrHashMismatch       = errors.New("results hash mismatch")
+	ErrSignatureMismatch  = errors.New("results signature mismatch")
+	ErrSigningKeyRequired = errors.New("signed results require the signing key")
+	ErrRevisionReason     = errors.New("revision of official results requires a reason")
+	ErrNotFinal           = errors.New("results are not ready to be finalized")
+)
@@ -0,0 +1,166 @@
+package results
+
+import (
+	"biathlon/internal/entity"
+	"biathlon/internal/util"
+	"time"
+)
+
+type State string
+
+const (
+	// StateLive means competitors are still on the course.
+	StateLive State = "live"
+	// StateUnofficial means all competitors left the course and the
+	// protest window is open.
+	StateUnofficial State = "unofficial"
+	// StateProvisional means the protest window is closed and the
+	// results wait to be finalized.
+	StateProvisional State = "provisional"
+	// StateOfficial means the results are finalized.
+	StateOfficial State = "official"
+)
+
+type Lap struct {
+	Time  string  `json:"time,omitempty"`
+	Speed float32 `json:"speed,omitempty"`
+	// CourseTime is the lap time without the range and penalty loops,
+	// CourseRank the rank by it among all competitors who completed the lap.
+	CourseTime string `json:"courseTime,omitempty"`
+	CourseRank int    `json:"courseRank,omitempty"`
+}
+
+// Row is a structured line of the final report.
+type Row struct {
+	Rank         int    `json:"rank,omitempty"`
+	CompetitorID int64  `json:"competitor"`
+	Status       string `json:"status"`
+	Code         string `json:"code,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+	Rule         string `json:"rule,omitempty"`
+	TotalTime    string `json:"totalTime,omitempty"`
+	Gap          string `json:"gap,omitempty"`
+	LapsBehind   int    `json:"lapsBehind,omitempty"`
+	TimePenalty  string `json:"timePenalty,omitempty"`
+	Laps         []Lap  `json:"laps"`
+	Penalty      Lap    `json:"penalty"`
+	Hits         int    `json:"hits"`
+	Shots        int    `json:"shots"`
+	// ProjectedTime and ProjectedRank are set for competitors on the course.
+	ProjectedTime string `json:"projectedTime,omitempty"`
+	ProjectedRank int    `json:"projectedRank,omitempty"`
+	// CourseTime and CourseRank are set for finishers.
+	CourseTime string `json:"courseTime,omitempty"`
+	CourseRank int    `json:"courseRank,omitempty"`
+}
+
+func NewRow(s entity.Standing) Row {
+	c := s.Competitor
+	status := c.ReportStatus()
+	res := Row{
+		Rank:         s.Rank,
+		CompetitorID: c.ID,
+		Status:       status.String(),
+		Code:         status.Code(),
+		Reason:       c.StatusReason,
+		Rule:         string(c.Rule),
+		LapsBehind:   s.LapsBehind,
+		Laps:         make([]Lap, len(c.MainLapsData)),
+		Hits:         c.HitedTargets,
+		Shots:        c.TotalTargets,
+	}
+
+	if c.Status == entity.StatusFinished {
+		res.TotalTime = util.FormatDuration(c.TotalTime())
+		res.Gap = util.FormatGap(s.Gap)
+	}
+
+	if d, ok := c.CourseTime(); ok {
+		res.CourseTime = util.FormatDuration(d)
+		res.CourseRank = s.CourseRank
+	}
+
+	if c.Status == entity.StatusStarted && c.Projection != nil {
+		res.ProjectedTime = util.FormatDuration(c.Projection.TotalTime)
+		res.ProjectedRank = s.ProjectedRank
+	}
+
+	if c.TimePenalty != 0 {
+		res.TimePenalty = util.FormatDuration(c.TimePenalty)
+	}
+
+	for i, l := range c.MainLapsData {
+		if d, ok := l.Duration(); ok {
+			res.Laps[i] = Lap{Time: util.FormatDuration(d), Speed: util.GetAverageSpeed(d, l.Size)}
+		}
+		if d, ok := c.LapCourseTime(i + 1); ok {
+			res.Laps[i].CourseTime = util.FormatDuration(d)
+		}
+		if i < len(s.LapCourseRanks) {
+			res.Laps[i].CourseRank = s.LapCourseRanks[i]
+		}
+	}
+
+	var penalty time.Duration
+	for _, l := range c.PenaltyLapData {
+		if d, ok := l.Duration(); ok {
+			penalty += d
+		}
+	}
+	res.Penalty = Lap{Time: util.FormatDuration(penalty), Speed: util.GetAverageSpeed(penalty, c.Penalty)}
+
+	return res
+}
+
+func NewRows(standings []entity.Standing) []Row {
+	var res []Row = make([]Row, len(standings))
+	for i, s := range standings {
+		res[i] = NewRow(s)
+	}
+	return res
+}
+
+// lastOffCourse returns the latest time a competitor left the course,
+// false if nobody has finished or has been pulled out yet.
+func lastOffCourse(standings []entity.Standing) (time.Time, bool) {
+	var res time.Time
+	found := false
+	for _, s := range standings {
+		c := s.Competitor
+		if c.Status == entity.StatusRegistered || c.StatusEvent == nil {
+			continue
+		}
+
+		if !found || c.StatusEvent.Timestamp.After(res) {
+			res = c.StatusEvent.Timestamp
+		}
+		found = true
+	}
+	return res, found
+}
+
+// StateOf determines the state of not finalized results at the given race
+// clock time from the competitors statuses and the protest window.
+func StateOf(standings []entity.Standing, clock time.Time, protestWindow time.Duration) State {
+	for _, s := range standings {
+		if s.Competitor.Status == entity.StatusStarted {
+			return StateLive
+		}
+	}
+
+	lastOff, ok := lastOffCourse(standings)
+	if !ok {
+		return StateLive
+	}
+
+	if clock.Before(lastOff.Add(protestWindow)) {
+		return StateUnofficial
+	}
+	return StateProvisional
+}
+
+// ProtestDeadline returns the end of the protest window.
+func ProtestDeadline(standings []entity.Standing, protestWindow time.Duration) time.Time {
+	lastOff, _ := lastOffCourse(standings)
+	return lastOff.Add(protestWindow)
+}
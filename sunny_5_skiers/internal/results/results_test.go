@@ -0,0 +1,168 @@
+package results
+
+import (
+	"biathlon/internal/entity"
+	"biathlon/internal/util"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResults(t *testing.T) {
+	t.Parallel()
+	at := func(s string) time.Time {
+		ts, err := util.ConvertToTimestamp(s)
+		require.NoError(t, err)
+		return ts
+	}
+
+	finished := &entity.Competitior{
+		ID:                 1,
+		Status:             entity.StatusFinished,
+		StatusEvent:        &entity.Event{Timestamp: at("10:30:00.000")},
+		ScheduledStartTime: at("10:00:00.000"),
+		FinishRaceTime:     at("10:30:00.000"),
+	}
+	racing := &entity.Competitior{ID: 2, Status: entity.StatusStarted}
+	dnf := &entity.Competitior{ID: 2, Status: entity.StatusDNF, StatusEvent: &entity.Event{Timestamp: at("10:20:00.000")}}
+
+	t.Run("state test", func(t *testing.T) {
+		t.Parallel()
+		window := 15 * time.Minute
+
+		live := []entity.Standing{{Rank: 1, Competitor: finished}, {Competitor: racing}}
+		require.Equal(t, StateLive, StateOf(live, at("10:50:00.000"), window))
+
+		off := []entity.Standing{{Rank: 1, Competitor: finished}, {Competitor: dnf}}
+		require.Equal(t, StateUnofficial, StateOf(off, at("10:40:00.000"), window))
+		require.Equal(t, StateProvisional, StateOf(off, at("10:45:00.000"), window))
+		require.Equal(t, at("10:45:00.000"), ProtestDeadline(off, window))
+
+		notStarted := []entity.Standing{{Competitor: &entity.Competitior{ID: 3}}}
+		require.Equal(t, StateLive, StateOf(notStarted, at("10:50:00.000"), window))
+	})
+
+	t.Run("finalize test", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		key := []byte("secret")
+		rows := NewRows([]entity.Standing{{Rank: 1, Competitor: finished}, {Competitor: dnf}})
+
+		sheet, path, err := Finalize(dir, &Sheet{Clock: "10:50:00.000", Rows: rows}, key)
+		require.NoError(t, err)
+		require.Equal(t, 1, sheet.Version)
+		require.Equal(t, StateOfficial, sheet.State)
+		require.Equal(t, filepath.Join(dir, "official.v1.json"), path)
+
+		stored, err := ReadSheet(path)
+		require.NoError(t, err)
+		require.NoError(t, stored.Verify(key))
+		require.ErrorIs(t, stored.Verify([]byte("other")), ErrSignatureMismatch)
+		require.ErrorIs(t, stored.Verify(nil), ErrSigningKeyRequired)
+
+		same, _, err := Finalize(dir, &Sheet{Clock: "10:55:00.000", Rows: rows}, key)
+		require.NoError(t, err)
+		require.Equal(t, stored.Hash, same.Hash)
+
+		revised := NewRows([]entity.Standing{{Rank: 1, Competitor: finished}})
+		_, _, err = Finalize(dir, &Sheet{Clock: "11:00:00.000", Rows: revised}, key)
+		require.ErrorIs(t, err, ErrRevisionReason)
+
+		sheet, path, err = Finalize(dir, &Sheet{Clock: "11:00:00.000", Reason: "protest accepted", Rows: revised}, key)
+		require.NoError(t, err)
+		require.Equal(t, 2, sheet.Version)
+		require.Equal(t, stored.Hash, sheet.Supersedes)
+
+		stored.Rows[0].Rank = 2
+		require.ErrorIs(t, stored.Verify(key), ErrHashMismatch)
+
+		_, _, err = Finalize(dir, &Sheet{Clock: "11:05:00.000", Reason: "unsigned", Rows: rows}, nil)
+		require.ErrorIs(t, err, ErrSigningKeyRequired)
+
+		// A tampered version with the signature removed and the hash
+		// recomputed cannot be built on without the key either.
+		tampered, err := ReadSheet(path)
+		require.NoError(t, err)
+		tampered.Rows[0].Rank = 2
+		require.NoError(t, tampered.Seal(nil))
+		require.NoError(t, tampered.Verify(nil))
+		data, err := json.Marshal(tampered)
+		require.NoError(t, err)
+		require.NoError(t, os.Chmod(path, 0o644))
+		require.NoError(t, os.WriteFile(path, data, 0o644))
+		_, _, err = Finalize(dir, &Sheet{Clock: "11:05:00.000", Reason: "unsigned", Rows: rows}, nil)
+		require.ErrorIs(t, err, ErrSigningKeyRequired)
+		_, _, err = Finalize(dir, &Sheet{Clock: "11:05:00.000", Reason: "again", Rows: rows}, key)
+		require.ErrorIs(t, err, ErrSignatureMismatch)
+
+		require.NoError(t, os.WriteFile(path, []byte(`{"version":2,"results":[],"hash":"0"}`), 0o644))
+		_, _, err = Finalize(dir, &Sheet{Clock: "11:05:00.000", Reason: "again", Rows: rows}, key)
+		require.ErrorIs(t, err, ErrHashMismatch)
+	})
+
+	t.Run("unsigned finalize test", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		rows := NewRows([]entity.Standing{{Rank: 1, Competitor: finished}, {Competitor: dnf}})
+
+		_, _, err := Finalize(dir, &Sheet{Clock: "10:50:00.000", Rows: rows}, nil)
+		require.NoError(t, err)
+
+		revised := NewRows([]entity.Standing{{Rank: 1, Competitor: finished}})
+		sheet, _, err := Finalize(dir, &Sheet{Clock: "11:00:00.000", Reason: "protest accepted", Rows: revised}, nil)
+		require.NoError(t, err)
+		require.Equal(t, 2, sheet.Version)
+		require.Empty(t, sheet.Signature)
+	})
+
+	t.Run("diff test", func(t *testing.T) {
+		t.Parallel()
+		before := []Row{
+			{Rank: 1, CompetitorID: 1, Status: "Finished", TotalTime: "00:30:00.000"},
+			{Rank: 2, CompetitorID: 2, Status: "Finished", TotalTime: "00:31:00.000"},
+			{CompetitorID: 3, Status: "Started"},
+		}
+		after := []Row{
+			{Rank: 1, CompetitorID: 2, Status: "Finished", TotalTime: "00:29:30.000"},
+			{Rank: 2, CompetitorID: 1, Status: "Finished", TotalTime: "00:30:00.000"},
+			{CompetitorID: 3, Status: "Started"},
+			{CompetitorID: 4, Status: "NotStarted"},
+		}
+
+		changes := Diff(before, after)
+		require.Len(t, changes, 3)
+		require.Equal(t, -1, changes[0].RankChange)
+		require.Empty(t, changes[0].TimeDelta)
+		require.Equal(t, 1, changes[1].RankChange)
+		require.Equal(t, "-00:01:30.000", changes[1].TimeDelta)
+		require.Equal(t, []string{"rank", "totalTime"}, changes[1].Fields)
+		require.Equal(t, &Delta{Before: "00:31:00.000", After: "00:29:30.000", Delta: "-00:01:30.000"}, changes[1].TotalTime)
+		require.Nil(t, changes[2].Before)
+
+		before = []Row{{Rank: 1, CompetitorID: 1, Status: "Finished", TotalTime: "00:30:00.000",
+			Laps: []Lap{{Time: "00:15:00.000"}, {Time: "00:15:00.000"}}, Hits: 9, Shots: 10}}
+		after = []Row{{CompetitorID: 1, Status: "Disqualified",
+			Laps: []Lap{{Time: "00:15:00.000"}, {Time: "00:14:50.000"}}, Hits: 10, Shots: 10}}
+
+		changes = Diff(before, after)
+		require.Len(t, changes, 1)
+		require.Equal(t, &Delta{Before: "Finished", After: "Disqualified"}, changes[0].Status)
+		require.Equal(t, []LapDelta{{Lap: 2, Delta: Delta{Before: "00:15:00.000", After: "00:14:50.000", Delta: "-00:00:10.000"}}}, changes[0].Laps)
+		require.Equal(t, &Delta{Before: "9/10", After: "10/10"}, changes[0].Shooting)
+		require.Zero(t, changes[0].RankChange)
+
+		var buf strings.Builder
+		require.NoError(t, WriteDiff(&buf, changes))
+		require.Equal(t, `competitor(1): 1 00:30:00.000 -> - Disqualified (rank, status, totalTime, laps, hits)
+  status: Finished -> Disqualified
+  total: 00:30:00.000 -> none
+  lap 2: 00:15:00.000 -> 00:14:50.000 (-00:00:10.000)
+  shooting: 9/10 -> 10/10
+`, buf.String())
+	})
+}
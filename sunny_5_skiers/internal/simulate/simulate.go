@@ -0,0 +1,246 @@
+package simulate
+
+import (
+	"biathlon/config"
+	"biathlon/internal/draw"
+	"biathlon/internal/entity"
+	"biathlon/internal/util"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	targets = 5
+	// rangeShare is the part of the lap skied before the firing range.
+	rangeShare = 0.55
+)
+
+// Athlete is the performance profile of a simulated competitor: the
+// average ski speed in m/s and the probability to hit a target.
+type Athlete struct {
+	CompetitorID int64   `json:"competitor"`
+	Speed        float64 `json:"speed"`
+	Accuracy     float64 `json:"accuracy"`
+}
+
+// ReadAthletes reads a JSON array of athlete profiles.
+func ReadAthletes(r io.Reader) ([]Athlete, error) {
+	var res []Athlete
+	err := json.NewDecoder(r).Decode(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range res {
+		if a.Speed <= 0 || a.Accuracy < 0 || a.Accuracy > 1 {
+			return nil, fmt.Errorf("competitor(%d): %w", a.CompetitorID, ErrIncorrectAthlete)
+		}
+	}
+	return res, nil
+}
+
+type Options struct {
+	// Competitors is the field size used when no Athletes are given.
+	Competitors int
+	Athletes    []Athlete
+	Seed        uint64
+	// DNFRate and LateStartRate are the probabilities of a competitor
+	// not finishing and of starting outside of the start interval.
+	DNFRate       float64
+	LateStartRate float64
+}
+
+// Athletes generates a field of numbered athletes with random profiles.
+func Athletes(n int, seed uint64) ([]Athlete, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrIncorrectFieldSize, n)
+	}
+
+	rnd := rand.New(rand.NewPCG(seed, 1))
+	var res []Athlete = make([]Athlete, n)
+	for i := range res {
+		res[i] = Athlete{
+			CompetitorID: int64(i + 1),
+			Speed:        4.6 + rnd.NormFloat64()*0.25,
+			Accuracy:     min(0.98, 0.72+rnd.Float64()*0.24),
+		}
+	}
+	return res, nil
+}
+
+type generator struct {
+	cfg    *config.Config
+	opts   Options
+	rnd    *rand.Rand
+	events []*entity.Event
+	delta  time.Duration
+}
+
+// Generate produces a chronologically ordered incoming event stream of a
+// race for the config. The same config and options always produce the
+// same stream.
+func Generate(cfg *config.Config, opts Options) ([]*entity.Event, error) {
+	start, err := util.ConvertToTimestamp(cfg.Start)
+	if err != nil {
+		return nil, err
+	}
+
+	delta, err := util.ConvertToDuration(cfg.StartDelta)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Laps <= 0 || cfg.LapLen <= 0 {
+		return nil, ErrIncorrectCourse
+	}
+
+	athletes := opts.Athletes
+	if len(athletes) == 0 {
+		athletes, err = Athletes(opts.Competitors, opts.Seed)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	g := &generator{
+		cfg:   cfg,
+		opts:  opts,
+		rnd:   rand.New(rand.NewPCG(opts.Seed, 2)),
+		delta: delta,
+	}
+
+	roster := make([]draw.Entry, len(athletes))
+	profiles := make(map[int64]Athlete, len(athletes))
+	registration := start.Add(-time.Hour)
+	for i, a := range athletes {
+		roster[i] = draw.Entry{CompetitorID: a.CompetitorID, Group: 1}
+		profiles[a.CompetitorID] = a
+		at := registration.Add(time.Duration(i) * 30 * time.Minute / time.Duration(len(athletes)))
+		g.add(at, 1, a.CompetitorID, "")
+	}
+
+	slots := draw.Draw(roster, start, delta, opts.Seed)
+	for _, s := range slots {
+		if cfg.Format == config.FormatMassStart {
+			s.StartTime = start
+		}
+		g.add(start.Add(-20*time.Minute), 2, s.CompetitorID, util.FormatTimestamp(s.StartTime))
+		g.race(profiles[s.CompetitorID], s.StartTime)
+	}
+
+	slices.SortStableFunc(g.events, func(a, b *entity.Event) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	return g.events, nil
+}
+
+func (g *generator) add(at time.Time, kind int64, id int64, param string) {
+	g.events = append(g.events, &entity.Event{
+		Timestamp:       at.Truncate(time.Millisecond),
+		Kind:            kind,
+		CompetitorID:    id,
+		AdditionalParam: param,
+	})
+}
+
+// seconds returns a random duration uniformly distributed between the
+// given numbers of seconds.
+func (g *generator) seconds(lo, hi float64) time.Duration {
+	return time.Duration((lo + g.rnd.Float64()*(hi-lo)) * float64(time.Second))
+}
+
+// ski returns the time to ski the distance with a random day form.
+func (g *generator) ski(a Athlete, meters float64) time.Duration {
+	speed := max(1, a.Speed*(1+g.rnd.NormFloat64()*0.02))
+	return time.Duration(meters / speed * float64(time.Second))
+}
+
+func (g *generator) race(a Athlete, scheduled time.Time) {
+	id := a.CompetitorID
+	g.add(scheduled.Add(-g.seconds(15, 45)), 3, id, "")
+
+	if g.rnd.Float64() < g.opts.LateStartRate {
+		g.add(scheduled.Add(g.delta+g.seconds(1, 60)), 4, id, "")
+		return
+	}
+	now := scheduled.Add(g.seconds(0, 1))
+	g.add(now, 4, id, "")
+
+	dnfLap := -1
+	if g.rnd.Float64() < g.opts.DNFRate {
+		dnfLap = g.rnd.IntN(g.cfg.Laps)
+	}
+
+	lapLen := float64(g.cfg.LapLen)
+	for lap := range g.cfg.Laps {
+		if lap == dnfLap {
+			now = now.Add(g.ski(a, lapLen*g.rnd.Float64()))
+			g.add(now, 11, id, dnfReasons[g.rnd.IntN(len(dnfReasons))])
+			return
+		}
+
+		if g.cfg.FiringLines > 0 {
+			now = now.Add(g.ski(a, lapLen*rangeShare))
+			now = g.shoot(a, now)
+			now = now.Add(g.ski(a, lapLen*(1-rangeShare)))
+		} else {
+			now = now.Add(g.ski(a, lapLen))
+		}
+		g.add(now, 10, id, "")
+	}
+}
+
+// shoot simulates a range visit with the penalty loops and returns the
+// time the competitor is back on the course.
+func (g *generator) shoot(a Athlete, now time.Time) time.Time {
+	id := a.CompetitorID
+	g.add(now, 5, id, strconv.Itoa(1+g.rnd.IntN(g.cfg.FiringLines)))
+
+	now = now.Add(g.seconds(15, 25))
+	misses := 0
+	for target := 1; target <= targets; target++ {
+		now = now.Add(g.seconds(2.5, 5))
+		if g.rnd.Float64() < a.Accuracy {
+			g.add(now, 6, id, strconv.Itoa(target))
+		} else {
+			misses++
+		}
+	}
+
+	now = now.Add(g.seconds(2, 4))
+	g.add(now, 7, id, "")
+	if misses == 0 || g.cfg.PenaltyLen <= 0 {
+		return now
+	}
+
+	now = now.Add(g.seconds(5, 15))
+	g.add(now, 8, id, "")
+	now = now.Add(g.ski(a, float64(misses*g.cfg.PenaltyLen)))
+	g.add(now, 9, id, "")
+	return now
+}
+
+var dnfReasons = []string{"broken ski", "broken pole", "illness", "fall"}
+
+// Write writes the events in the incoming events file format.
+func Write(w io.Writer, events []*entity.Event) error {
+	for _, e := range events {
+		_, err := fmt.Fprintln(w, e.Incoming())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	ErrIncorrectCourse    = errors.New("config must have positive laps and lap length")
+	ErrIncorrectAthlete   = errors.New("athlete speed must be positive and accuracy between 0 and 1")
+	ErrIncorrectFieldSize = errors.New("number of competitors must not be negative")
+)
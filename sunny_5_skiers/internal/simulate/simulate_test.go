@@ -0,0 +1,85 @@
+package simulate
+
+import (
+	"biathlon/config"
+	"biathlon/internal/entity"
+	"biathlon/internal/processor"
+	"biathlon/internal/validator"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Config{
+		Laps: 3, LapLen: 3000, PenaltyLen: 150, FiringLines: 2,
+		Start: "10:00:00.000", StartDelta: "00:00:30",
+	}
+	opts := Options{Competitors: 60, Seed: 42, DNFRate: 0.1, LateStartRate: 0.1}
+
+	t.Run("deterministic test", func(t *testing.T) {
+		t.Parallel()
+		write := func(opts Options) string {
+			events, err := Generate(cfg, opts)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			require.NoError(t, Write(&buf, events))
+			return buf.String()
+		}
+
+		require.Equal(t, write(opts), write(opts))
+		other := opts
+		other.Seed = 43
+		require.NotEqual(t, write(opts), write(other))
+	})
+
+	t.Run("processing test", func(t *testing.T) {
+		t.Parallel()
+		events, err := Generate(cfg, opts)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, events))
+
+		logger := zap.NewNop()
+		proc := processor.New(cfg, logger)
+		v := validator.New(logger, cfg, proc)
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		for i, line := range lines {
+			require.NoError(t, v.Validate(line), "line %d: %s", i+1, line)
+			if i > 0 {
+				require.False(t, events[i].Timestamp.Before(events[i-1].Timestamp))
+			}
+		}
+
+		statuses := make(map[entity.Status]int)
+		for _, s := range proc.GetStandings() {
+			statuses[s.Competitor.Status]++
+		}
+		require.Len(t, proc.GetStandings(), 60)
+		require.Greater(t, statuses[entity.StatusFinished], 40)
+		require.Positive(t, statuses[entity.StatusDNF])
+		require.Positive(t, statuses[entity.StatusDSQ])
+	})
+
+	t.Run("course test", func(t *testing.T) {
+		t.Parallel()
+		_, err := Generate(&config.Config{Start: "10:00:00.000", StartDelta: "00:00:30"}, opts)
+		require.ErrorIs(t, err, ErrIncorrectCourse)
+	})
+
+	t.Run("field size test", func(t *testing.T) {
+		t.Parallel()
+		_, err := Generate(cfg, Options{Competitors: -1})
+		require.ErrorIs(t, err, ErrIncorrectFieldSize)
+
+		athletes, err := Athletes(0, 42)
+		require.NoError(t, err)
+		require.Empty(t, athletes)
+	})
+}
@@ -0,0 +1,73 @@
+// Package tracing sets up the OpenTelemetry SDK to export the spans of the
+// event processing with the stdout trace exporter, so the exported spans
+// can be read offline.
+package tracing
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the exported spans.
+const ScopeName = "biathlon"
+
+// Provider exports every span as a JSON line to the writer when it ends.
+type Provider struct {
+	*sdktrace.TracerProvider
+	exporter *exporter
+}
+
+// NewProvider creates a provider writing the spans to w, a file or stdout.
+func NewProvider(w io.Writer) (*Provider, error) {
+	stdout, err := stdouttrace.New(stdouttrace.WithWriter(w))
+	if err != nil {
+		return nil, err
+	}
+
+	e := &exporter{SpanExporter: stdout}
+	return &Provider{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(e)),
+		exporter:       e,
+	}, nil
+}
+
+// Err returns the first export error, the spans after it are dropped.
+func (p *Provider) Err() error {
+	p.exporter.mu.Lock()
+	defer p.exporter.mu.Unlock()
+	return p.exporter.err
+}
+
+// exporter keeps the first error of the wrapped exporter.
+type exporter struct {
+	sdktrace.SpanExporter
+	mu  sync.Mutex
+	err error
+}
+
+func (e *exporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.err != nil {
+		return nil
+	}
+	e.err = e.SpanExporter.ExportSpans(ctx, spans)
+	return e.err
+}
+
+// RecordError adds an exception event and marks the span failed if err
+// is not nil.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type failingWriter struct {
+	calls int
+}
+
+func (w *failingWriter) Write([]byte) (int, error) {
+	w.calls++
+	return 0, errors.New("disk full")
+}
+
+func TestTracing(t *testing.T) {
+	t.Parallel()
+
+	t.Run("export test", func(t *testing.T) {
+		t.Parallel()
+		var b strings.Builder
+		provider, err := NewProvider(&b)
+		require.NoError(t, err)
+		tracer := provider.Tracer(ScopeName)
+
+		ctx, root := tracer.Start(context.Background(), "Validate")
+		root.SetAttributes(attribute.Int("event.line", 1), attribute.StringSlice("event.outgoing", nil))
+		_, child := tracer.Start(ctx, "Process")
+		RecordError(child, errors.New("competitor not found"))
+		child.End()
+		RecordError(root, nil)
+		root.End()
+		require.NoError(t, provider.Shutdown(context.Background()))
+		require.NoError(t, provider.Err())
+
+		lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+		require.Len(t, lines, 2)
+
+		var spans [2]map[string]any
+		for i, l := range lines {
+			require.NoError(t, json.Unmarshal([]byte(l), &spans[i]))
+		}
+		require.Equal(t, "Process", spans[0]["Name"])
+		require.Equal(t, "Validate", spans[1]["Name"])
+		require.Equal(t, spans[1]["SpanContext"].(map[string]any)["SpanID"], spans[0]["Parent"].(map[string]any)["SpanID"])
+		require.Equal(t, "00000000000000000000000000000000", spans[1]["Parent"].(map[string]any)["TraceID"])
+		require.Equal(t, map[string]any{"Code": "Error", "Description": "competitor not found"}, spans[0]["Status"])
+		require.Equal(t, "Unset", spans[1]["Status"].(map[string]any)["Code"])
+		require.Equal(t, ScopeName, spans[1]["InstrumentationScope"].(map[string]any)["Name"])
+
+		require.Contains(t, lines[1], `{"Key":"event.line","Value":{"Type":"INT64","Value":1}}`)
+		require.Contains(t, lines[0], `"Events":[{"Name":"exception","Attributes":[{"Key":"exception.type"`)
+	})
+
+	t.Run("export error test", func(t *testing.T) {
+		t.Parallel()
+		w := &failingWriter{}
+		provider, err := NewProvider(w)
+		require.NoError(t, err)
+		for range 3 {
+			_, span := provider.Tracer(ScopeName).Start(context.Background(), "Validate")
+			span.End()
+		}
+		require.EqualError(t, provider.Err(), "disk full")
+		require.Equal(t, 1, w.calls)
+	})
+}
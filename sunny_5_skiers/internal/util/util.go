@@ -11,6 +11,33 @@ func ConvertToTimestamp(s string) (time.Time, error) {
 	return time.Parse(layout, s)
 }
 
+// ConvertToDuration parses a HH:MM:SS duration such as the start delta.
+func ConvertToDuration(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04:05", s)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second +
+		time.Duration(t.Nanosecond()), nil
+}
+
+// ParseDuration parses a HH:MM:SS.sss duration as written by FormatDuration.
+func ParseDuration(s string) (time.Duration, error) {
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return 0, err
+	}
+
+	return t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())), nil
+}
+
+func FormatTimestamp(t time.Time) string {
+	return t.Format(layout)
+}
+
 func GetTimeDiffString(a, b time.Time) string {
 	return FormatDuration(GetTimeDiff(a, b))
 }
@@ -20,6 +47,9 @@ func GetTimeDiff(a, b time.Time) time.Duration {
 }
 
 func GetAverageSpeed(d time.Duration, size int) float32 {
+	if size == 0 {
+		return 0
+	}
 	return float32(d.Seconds()) / float32(size)
 }
 
@@ -37,3 +67,27 @@ func FormatDuration(d time.Duration) string {
 	millis := int64(d / time.Millisecond)
 	return fmt.Sprintf("%02d:%02d:%02d.%03d", hours, minutes, seconds, millis)
 }
+
+// FormatGap formats a time behind the leader as +mm:ss.s, adding
+// hours only when the gap exceeds one hour. Tenths are truncated.
+func FormatGap(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	tenths := int64(d / (100 * time.Millisecond))
+	hours := tenths / 36000
+	minutes := tenths / 600 % 60
+	seconds := tenths / 10 % 60
+	if hours > 0 {
+		return fmt.Sprintf("+%d:%02d:%02d.%d", hours, minutes, seconds, tenths%10)
+	}
+	return fmt.Sprintf("+%02d:%02d.%d", minutes, seconds, tenths%10)
+}
+
+// FormatDelta formats a signed time difference as +HH:MM:SS.sss.
+func FormatDelta(d time.Duration) string {
+	if d < 0 {
+		return "-" + FormatDuration(d)
+	}
+	return "+" + FormatDuration(d)
+}
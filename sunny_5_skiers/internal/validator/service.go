@@ -2,8 +2,10 @@ package validator
 
 import (
 	"biathlon/config"
+	"biathlon/internal/entity"
 	"biathlon/internal/processor"
-
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/zap"
 )
 
@@ -11,6 +13,13 @@ type implementation struct {
 	logger    *zap.Logger
 	cfg       *config.Config
 	processor processor.Processor
+	line      int
+	startList startList
+	tracer    trace.Tracer
+	tracing   bool
+	// outgoing are the log entries of the last processed event, collected
+	// only while tracing.
+	outgoing []*entity.Event
 }
 
 func New(logger *zap.Logger, cfg *config.Config, processor processor.Processor) *implementation {
@@ -18,5 +27,19 @@ func New(logger *zap.Logger, cfg *config.Config, processor processor.Processor)
 		logger:    logger,
 		cfg:       cfg,
 		processor: processor,
+		startList: newStartList(),
+		tracer:    noop.NewTracerProvider().Tracer(""),
+	}
+}
+
+// Trace records a span of every validated event with the child spans of
+// parsing and processing it.
+func (i *implementation) Trace(tracer trace.Tracer) {
+	if !i.tracing {
+		i.processor.Subscribe(func(_ *entity.Event, logged []*entity.Event, _ error) {
+			i.outgoing = append(i.outgoing, logged...)
+		})
 	}
+	i.tracer = tracer
+	i.tracing = true
 }
@@ -0,0 +1,128 @@
+package validator
+
+import (
+	"biathlon/config"
+	"biathlon/internal/entity"
+	"biathlon/internal/util"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// startList keeps the drawn start times to check that every new draw
+// fits the start grid defined by the config.
+type startList struct {
+	startTimes map[int64]time.Time
+	slots      map[time.Time]int64
+	started    map[int64]struct{}
+}
+
+func newStartList() startList {
+	return startList{
+		startTimes: make(map[int64]time.Time),
+		slots:      make(map[time.Time]int64),
+		started:    make(map[int64]struct{}),
+	}
+}
+
+// checkDraw returns all inconsistencies of the start time drawn by the event.
+func (i *implementation) checkDraw(event *entity.Event) error {
+	startTime, err := util.ConvertToTimestamp(event.AdditionalParam)
+	if err != nil {
+		return errors.New("incorrect start time format")
+	}
+
+	first, err := util.ConvertToTimestamp(i.cfg.Start)
+	if err != nil {
+		return errors.New("incorrect config start time")
+	}
+
+	delta, err := util.ConvertToDuration(i.cfg.StartDelta)
+	if err != nil {
+		return errors.New("incorrect config start delta")
+	}
+
+	var errs []error
+	if _, ok := i.startList.started[event.CompetitorID]; ok {
+		errs = append(errs, ErrDrawAfterStart)
+	}
+
+	if startTime.Before(first) {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrStartBeforeFirstStart, event.AdditionalParam))
+	} else if delta > 0 && startTime.Sub(first)%delta != 0 {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrStartOffGrid, event.AdditionalParam))
+	}
+
+	if owner, ok := i.startList.slots[startTime]; ok && owner != event.CompetitorID {
+		errs = append(errs, fmt.Errorf("%w: %s is taken by competitor(%d)", ErrDuplicateStartTime, event.AdditionalParam, owner))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (i *implementation) validateDraw(event *entity.Event) error {
+	if i.cfg.StartListStrictness == config.StrictnessOff {
+		return nil
+	}
+
+	err := i.checkDraw(event)
+	if err == nil {
+		return nil
+	}
+
+	if i.cfg.StartListStrictness == config.StrictnessError {
+		return err
+	}
+
+	i.logger.Warn("inconsistent start list",
+		zap.Int("line", event.Line),
+		zap.Int64("competitor", event.CompetitorID),
+		zap.Error(err))
+	return nil
+}
+
+// record adds the draw or the start of the accepted event.
+func (s startList) record(event *entity.Event) {
+	switch event.Kind {
+	case 2:
+		s.recordDraw(event)
+	case 4:
+		s.started[event.CompetitorID] = struct{}{}
+	}
+}
+
+func (s startList) recordDraw(event *entity.Event) {
+	startTime, err := util.ConvertToTimestamp(event.AdditionalParam)
+	if err != nil {
+		return
+	}
+
+	if previous, ok := s.startTimes[event.CompetitorID]; ok && s.slots[previous] == event.CompetitorID {
+		delete(s.slots, previous)
+	}
+	s.startTimes[event.CompetitorID] = startTime
+	if _, ok := s.slots[startTime]; !ok {
+		s.slots[startTime] = event.CompetitorID
+	}
+}
+
+// loggedStartList rebuilds the start list from the processor log without
+// the event on the skipped line, zero skips nothing.
+func (i *implementation) loggedStartList(skip int) startList {
+	list := newStartList()
+	for _, event := range i.processor.GetLog() {
+		if skip == 0 || event.Line != skip {
+			list.record(event)
+		}
+	}
+	return list
+}
+
+var (
+	ErrStartBeforeFirstStart = errors.New("start time is before the first start")
+	ErrStartOffGrid          = errors.New("start time is not aligned to the start interval")
+	ErrDuplicateStartTime    = errors.New("start time is already drawn")
+	ErrDrawAfterStart        = errors.New("start time is drawn after the competitor started")
+)
@@ -2,44 +2,157 @@ package validator
 
 import (
 	"biathlon/internal/entity"
+	"biathlon/internal/eventlog"
+	"biathlon/internal/tracing"
 	"biathlon/internal/util"
+	"cmp"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"slices"
 	"strconv"
 	"strings"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
 func (i *implementation) Validate(rawData string) error {
+	i.line++
+	ctx, span := i.tracer.Start(context.Background(), "Validate")
+	defer span.End()
+	span.SetAttributes(attribute.Int("event.line", i.line), attribute.String("event.raw", rawData))
+
+	err := i.validate(ctx, span, rawData)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (i *implementation) validate(ctx context.Context, span trace.Span, rawData string) error {
+	_, parseSpan := i.tracer.Start(ctx, "parseEvent")
 	event, err := i.parseEvent(rawData)
+	tracing.RecordError(parseSpan, err)
+	if err != nil {
+		parseSpan.End()
+		return err
+	}
+	event.Line = i.line
+	parseSpan.SetAttributes(eventAttributes(event)...)
+	parseSpan.End()
+	span.SetAttributes(eventAttributes(event)...)
+
+	err = i.check(event)
+	if err != nil {
+		return err
+	}
+
+	err = i.process(ctx, event)
 	if err != nil {
 		return err
 	}
 
+	i.startList.record(event)
+	return nil
+}
+
+// process passes the event to the processor in a span with the log
+// entries it caused.
+func (i *implementation) process(ctx context.Context, event *entity.Event) error {
+	_, span := i.tracer.Start(ctx, "Process")
+	defer span.End()
+	span.SetAttributes(eventAttributes(event)...)
+
+	i.outgoing = nil
+	err := i.processor.Process(event)
+	tracing.RecordError(span, err)
+	if !span.IsRecording() {
+		return err
+	}
+
+	var outgoing []string = make([]string, len(i.outgoing))
+	for n, e := range i.outgoing {
+		var b strings.Builder
+		_ = eventlog.NewWriter(&b, eventlog.FormatText).Write(e)
+		outgoing[n] = strings.TrimSuffix(b.String(), "\n")
+	}
+	span.SetAttributes(
+		attribute.Int("event.outgoing.count", len(outgoing)),
+		attribute.StringSlice("event.outgoing", outgoing))
+	return err
+}
+
+func eventAttributes(event *entity.Event) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int64("event.kind", event.Kind),
+		attribute.Int64("event.competitor", event.CompetitorID),
+		attribute.String("event.timestamp", util.FormatTimestamp(event.Timestamp)),
+	}
+}
+
+// Edit retracts the event on the source line, replaces it with Event or,
+// without a line, inserts Event into the event stream. Reason is recorded
+// with the edit.
+type Edit struct {
+	Line   int
+	Event  string
+	Reason string
+}
+
+func (e Edit) String() string {
+	switch {
+	case e.Line == 0:
+		return "inserted " + e.Event
+	case e.Event == "":
+		return fmt.Sprintf("line %d retracted", e.Line)
+	default:
+		return fmt.Sprintf("line %d replaced", e.Line)
+	}
+}
+
+// Edit applies the edit to the processed event stream and returns the
+// results that changed. The start list is rebuilt from the recomputed
+// log, so a retracted or replaced draw frees its start time.
+func (i *implementation) Edit(edit Edit) ([]entity.ResultChange, error) {
+	defer func() {
+		i.startList = i.loggedStartList(0)
+	}()
+
+	if edit.Line != 0 && edit.Event == "" {
+		return i.processor.Retract(edit.Line, edit.Reason)
+	}
+
+	event, err := i.parseEvent(edit.Event)
+	if err != nil {
+		return nil, err
+	}
+	event.Line = edit.Line
+
+	// The replacement is checked against the start list without the
+	// replaced event.
+	i.startList = i.loggedStartList(edit.Line)
+	err = i.check(event)
+	if err != nil {
+		return nil, err
+	}
+
+	if edit.Line == 0 {
+		return i.processor.Insert(event, edit.Reason)
+	}
+	return i.processor.Replace(edit.Line, event, edit.Reason)
+}
+
+// check validates the event parameters and describes it.
+func (i *implementation) check(event *entity.Event) error {
+	var err error
 	switch event.Kind {
-	case 1:
-		startTime, err := util.ConvertToTimestamp(i.cfg.Start)
+	case 2:
+		err = i.validateDraw(event)
 		if err != nil {
-			return errors.New("incorrect event timestamp")
+			return err
 		}
-		event.Comment = fmt.Sprintf("The competitor(%d) registered", event.CompetitorID)
-
-		if startTime.Before(event.Timestamp) {
-			event = entity.DisqualificationEvent(event.CompetitorID, event.Timestamp)
-		}
-	case 2:
-		event.Comment =
-			fmt.Sprintf(
-				"The start time for the competitor(%d) was set by a draw to %s",
-				event.CompetitorID,
-				event.AdditionalParam)
-	case 3:
-		event.Comment = fmt.Sprintf("The competitor(%d) is on the start line", event.CompetitorID)
-	case 4:
-		event.Comment = fmt.Sprintf("The competitor(%d) has started", event.CompetitorID)
 	case 5:
-
 		flNumber, err := strconv.ParseInt(event.AdditionalParam, 10, 64)
 		if err != nil {
 			return errors.New("incorrect firing range format")
@@ -48,33 +161,24 @@ func (i *implementation) Validate(rawData string) error {
 		if flNumber > int64(i.cfg.FiringLines) {
 			return errors.New("number of fire line is more then the amount of firelines")
 		}
-
-		event.Comment = fmt.Sprintf("The competitor(%d) is on the firing range(%s)", event.CompetitorID, event.AdditionalParam)
-	case 6:
-		event.Comment = fmt.Sprintf("The target(%s) has been hit by competitior(%d)", event.AdditionalParam, event.CompetitorID)
-	case 7:
-		event.Comment = fmt.Sprintf("The competitor(%d) left the firing range", event.CompetitorID)
-	case 8:
-		event.Comment = fmt.Sprintf("The competitor(%d) entered the penalty laps", event.CompetitorID)
-	case 9:
-		event.Comment = fmt.Sprintf("The competitor(%d) left the penalty laps", event.CompetitorID)
-	case 10:
-		event.Comment = fmt.Sprintf("The competitor(%d) ended the main lap", event.CompetitorID)
-	case 11:
-		event.Comment = fmt.Sprintf("The competitor(%d) can`t continue: %s", event.CompetitorID, event.AdditionalParam)
-	default:
-		return entity.ErrUnexpectedKind
 	}
 
-	return i.processor.Process(event)
+	event.Comment, err = entity.DescribeIncoming(event)
+	return err
 }
 
-func (i *implementation) GetLog() {
-	println("log=============================")
-	for _, s := range i.processor.GetLog() {
-		fmt.Println(s)
-	}
-	println("log=============================")
+// Line returns the number of the last line passed to Validate.
+func (i *implementation) Line() int {
+	return i.line
+}
+
+// Close ends the event stream once the input is read.
+func (i *implementation) Close() {
+	i.processor.Close()
+}
+
+func (i *implementation) GetLog(w io.Writer, format eventlog.Format) error {
+	return eventlog.NewWriter(w, format).WriteAll(i.processor.GetLog())
 }
 
 func (i *implementation) GetResult() {
@@ -85,6 +189,88 @@ func (i *implementation) GetResult() {
 	println("result table====================")
 }
 
+func (i *implementation) GetSplits(w io.Writer) error {
+	for _, point := range i.processor.TimingPoints() {
+		_, err := fmt.Fprintf(w, "%s:\n", point)
+		if err != nil {
+			return err
+		}
+
+		for _, r := range i.processor.GetSplits(point) {
+			_, err = fmt.Fprintf(w, "%d %d %s +%s %+d\n",
+				r.Position,
+				r.CompetitorID,
+				util.FormatDuration(r.Elapsed),
+				util.FormatDuration(r.Gap),
+				r.PositionChange)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetProjections writes the projected rank and total time of the
+// competitors on the course ordered by the projected rank.
+func (i *implementation) GetProjections(w io.Writer) error {
+	var projected []entity.Standing
+	for _, s := range i.processor.GetStandings() {
+		if s.ProjectedRank > 0 {
+			projected = append(projected, s)
+		}
+	}
+
+	slices.SortStableFunc(projected, func(a, b entity.Standing) int {
+		return cmp.Compare(a.ProjectedRank, b.ProjectedRank)
+	})
+
+	for _, s := range projected {
+		_, err := fmt.Fprintf(w, "%d %d %s (at %s)\n",
+			s.ProjectedRank,
+			s.Competitor.ID,
+			util.FormatDuration(s.Competitor.Projection.TotalTime),
+			util.FormatTimestamp(s.Competitor.Projection.At))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetCourseTimes writes the rankings by course time for the whole race
+// and for every lap.
+func (i *implementation) GetCourseTimes(w io.Writer) error {
+	for lap := 0; lap <= i.cfg.Laps; lap++ {
+		rankings := i.processor.GetCourseTimes(lap)
+		if len(rankings) == 0 {
+			continue
+		}
+
+		var err error
+		if lap == 0 {
+			_, err = fmt.Fprintln(w, "course time:")
+		} else {
+			_, err = fmt.Fprintf(w, "lap %d course time:\n", lap)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, r := range rankings {
+			_, err = fmt.Fprintf(w, "%d %d %s %s\n",
+				r.Rank,
+				r.CompetitorID,
+				util.FormatDuration(r.CourseTime),
+				util.FormatGap(r.Gap))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (i *implementation) parseEvent(rawData string) (*entity.Event, error) {
 	var res = &entity.Event{}
 	splitedData := strings.Fields(rawData)
@@ -115,7 +301,7 @@ func (i *implementation) parseEvent(rawData string) (*entity.Event, error) {
 	}
 
 	if len(splitedData) > 3 {
-		res.AdditionalParam = splitedData[3]
+		res.AdditionalParam = strings.Join(splitedData[3:], " ")
 	}
 
 	return res, nil
@@ -2,11 +2,18 @@ package validator
 
 import (
 	"biathlon/config"
+	"biathlon/internal/entity"
 	"biathlon/internal/mocks"
+	proc "biathlon/internal/processor"
+	"biathlon/internal/tracing"
 	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap"
 )
@@ -92,6 +99,31 @@ func TestValidator(t *testing.T) {
 				errExpected:     true,
 				errProcExpected: false,
 			},
+			{
+				input:           "[10:30:00.000] 12 1 +00:02:00 shooting rule violation",
+				errExpected:     false,
+				errProcExpected: false,
+			},
+			{
+				input:           "[10:30:00.000] 12 1 2min",
+				errExpected:     true,
+				errProcExpected: false,
+			},
+			{
+				input:           "[10:30:00.000] 12 1 +00:00:00 no penalty",
+				errExpected:     true,
+				errProcExpected: false,
+			},
+			{
+				input:           "[10:30:00.000] 13 1 WINNER reason",
+				errExpected:     true,
+				errProcExpected: false,
+			},
+			{
+				input:           "[10:30:00.000] 14 1 5 time=10:00:00.000 timing error",
+				errExpected:     false,
+				errProcExpected: false,
+			},
 			{
 				input:           "[09:31:49.285] 1 3",
 				errExpected:     true,
@@ -114,4 +146,118 @@ func TestValidator(t *testing.T) {
 			}
 		}
 	})
+	t.Run("start list test", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		processor := mocks.NewMockProcessor(ctrl)
+		processor.EXPECT().Process(gomock.Any()).Return(nil).AnyTimes()
+
+		cfg := &config.Config{Start: "10:00:00.000", StartDelta: "00:01:30", StartListStrictness: "error"}
+		validator := New(l, cfg, processor)
+
+		require.NoError(t, validator.Validate("[09:50:00.000] 2 1 10:00:00.000"))
+		require.NoError(t, validator.Validate("[09:50:00.000] 2 2 10:01:30.000"))
+		require.ErrorIs(t, validator.Validate("[09:50:00.000] 2 3 10:01:30.000"), ErrDuplicateStartTime)
+		require.ErrorIs(t, validator.Validate("[09:50:00.000] 2 3 10:02:00.000"), ErrStartOffGrid)
+		require.ErrorIs(t, validator.Validate("[09:50:00.000] 2 3 09:58:30.000"), ErrStartBeforeFirstStart)
+		require.NoError(t, validator.Validate("[09:50:00.000] 2 1 10:03:00.000"))
+		require.NoError(t, validator.Validate("[09:50:00.000] 2 3 10:00:00.000"))
+		require.NoError(t, validator.Validate("[10:03:00.100] 4 1"))
+		require.ErrorIs(t, validator.Validate("[10:04:00.000] 2 1 10:04:30.000"), ErrDrawAfterStart)
+
+		cfg = &config.Config{Start: "10:00:00.000", StartDelta: "00:01:30", StartListStrictness: "warn"}
+		validator = New(l, cfg, processor)
+		require.NoError(t, validator.Validate("[09:50:00.000] 2 3 10:02:00.000"))
+	})
+	t.Run("start list edit test", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		processor := mocks.NewMockProcessor(ctrl)
+		processor.EXPECT().Process(gomock.Any()).Return(nil).AnyTimes()
+		log := []*entity.Event{
+			{Kind: 2, CompetitorID: 1, AdditionalParam: "10:00:00.000", Line: 1},
+			{Kind: 2, CompetitorID: 2, AdditionalParam: "10:01:30.000", Line: 2},
+		}
+		processor.EXPECT().GetLog().DoAndReturn(func() []*entity.Event { return log }).AnyTimes()
+		processor.EXPECT().Replace(2, gomock.Any(), "redraw").DoAndReturn(func(_ int, event *entity.Event, _ string) ([]entity.ResultChange, error) {
+			log[1] = event
+			return nil, nil
+		})
+		processor.EXPECT().Retract(2, "").DoAndReturn(func(int, string) ([]entity.ResultChange, error) {
+			log = log[:1]
+			return nil, nil
+		})
+
+		cfg := &config.Config{Start: "10:00:00.000", StartDelta: "00:01:30", StartListStrictness: "error"}
+		validator := New(l, cfg, processor)
+		require.NoError(t, validator.Validate("[09:50:00.000] 2 1 10:00:00.000"))
+		require.NoError(t, validator.Validate("[09:50:00.000] 2 2 10:01:30.000"))
+
+		_, err := validator.Edit(Edit{Line: 2, Event: "[09:50:00.000] 2 3 10:01:30.000", Reason: "redraw"})
+		require.NoError(t, err)
+		require.ErrorIs(t, validator.Validate("[09:50:00.000] 2 4 10:01:30.000"), ErrDuplicateStartTime)
+
+		_, err = validator.Edit(Edit{Line: 2})
+		require.NoError(t, err)
+		require.NoError(t, validator.Validate("[09:50:00.000] 2 4 10:01:30.000"))
+	})
+
+	t.Run("trace test", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		processor := mocks.NewMockProcessor(ctrl)
+
+		var listener proc.Listener
+		processor.EXPECT().Subscribe(gomock.Any()).Do(func(l proc.Listener) { listener = l })
+		processor.EXPECT().Process(gomock.Any()).DoAndReturn(func(e *entity.Event) error {
+			outgoing := &entity.Event{Timestamp: e.Timestamp, Kind: 33, CompetitorID: e.CompetitorID, Comment: "registered"}
+			listener(e, []*entity.Event{outgoing}, nil)
+			return nil
+		})
+		processor.EXPECT().Process(gomock.Any()).DoAndReturn(func(e *entity.Event) error {
+			listener(e, nil, entity.ErrCompetitorAlreadyExist)
+			return entity.ErrCompetitorAlreadyExist
+		})
+
+		recorder := tracetest.NewSpanRecorder()
+		validator := New(l, &config.Config{Start: "10:00:00.000"}, processor)
+		validator.Trace(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(tracing.ScopeName))
+
+		require.NoError(t, validator.Validate("[09:31:49.285] 1 3"))
+		require.Error(t, validator.Validate("[09:31:50.000] 1 3"))
+		require.Error(t, validator.Validate("garbage"))
+
+		spans := recorder.Ended()
+		var names []string
+		for _, span := range spans {
+			names = append(names, span.Name())
+		}
+		require.Equal(t, []string{
+			"parseEvent", "Process", "Validate",
+			"parseEvent", "Process", "Validate",
+			"parseEvent", "Validate",
+		}, names)
+
+		parse, process, root := spans[0], spans[1], spans[2]
+		require.Equal(t, root.SpanContext().SpanID(), parse.Parent().SpanID())
+		require.Equal(t, root.SpanContext().SpanID(), process.Parent().SpanID())
+		require.Equal(t, root.SpanContext().TraceID(), process.SpanContext().TraceID())
+		require.NotEqual(t, root.SpanContext().TraceID(), spans[5].SpanContext().TraceID())
+		require.Contains(t, root.Attributes(), attribute.Int("event.line", 1))
+		require.Contains(t, root.Attributes(), attribute.Int64("event.kind", 1))
+		require.Contains(t, parse.Attributes(), attribute.Int64("event.competitor", 3))
+		require.Contains(t, process.Attributes(), attribute.Int("event.outgoing.count", 1))
+		require.Contains(t, process.Attributes(), attribute.StringSlice("event.outgoing", []string{"[09:31:49.285] registered"}))
+		require.Equal(t, codes.Unset, root.Status().Code)
+
+		process, root = spans[4], spans[5]
+		require.Equal(t, codes.Error, process.Status().Code)
+		require.Equal(t, entity.ErrCompetitorAlreadyExist.Error(), root.Status().Description)
+		require.Equal(t, "exception", root.Events()[0].Name)
+
+		parse, root = spans[6], spans[7]
+		require.Equal(t, codes.Error, parse.Status().Code)
+		require.Contains(t, root.Attributes(), attribute.Int("event.line", 3))
+		require.Contains(t, root.Attributes(), attribute.String("event.raw", "garbage"))
+	})
 }
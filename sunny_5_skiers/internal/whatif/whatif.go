@@ -0,0 +1,91 @@
+package whatif
+
+import (
+	"biathlon/config"
+	"biathlon/internal/entity"
+	"biathlon/internal/processor"
+	"biathlon/internal/results"
+	"biathlon/internal/validator"
+	"bufio"
+	"fmt"
+	"io"
+
+	"go.uber.org/zap"
+)
+
+// Scenario is a hypothetical change of a race: config options set by
+// their JSON keys and edits of the event stream applied in order.
+type Scenario struct {
+	Overrides map[string]string
+	Edits     []validator.Edit
+}
+
+// Race is a processed race that can be cloned to evaluate scenarios.
+// A clone is built by processing the same events from scratch, so the
+// real race is never changed.
+type Race struct {
+	cfg    *config.Config
+	events []string
+	logger *zap.Logger
+}
+
+// Read reads the incoming events of the race.
+func Read(logger *zap.Logger, cfg *config.Config, r io.Reader) (*Race, error) {
+	var events []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		events = append(events, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		logger.Error("failed to read events", zap.Error(err))
+		return nil, err
+	}
+
+	return &Race{cfg: cfg, events: events, logger: logger}, nil
+}
+
+// Standings processes a clone of the race with the scenario applied.
+// Rejected events are skipped as in the real race, failed edits and
+// config overrides are returned as errors.
+func (r *Race) Standings(s Scenario) ([]entity.Standing, error) {
+	cfg := r.cfg
+	if len(s.Overrides) > 0 {
+		var err error
+		cfg, err = r.cfg.Override(s.Overrides)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	quiet := zap.NewNop()
+	proc := processor.New(cfg, quiet)
+	v := validator.New(quiet, cfg, proc)
+	for _, e := range r.events {
+		_ = v.Validate(e)
+	}
+
+	for _, edit := range s.Edits {
+		_, err := v.Edit(edit)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", edit, err)
+		}
+	}
+	return proc.GetStandings(), nil
+}
+
+// Compare returns the results of the scenario that differ from the real
+// results of the race.
+func (r *Race) Compare(s Scenario) ([]results.Change, error) {
+	actual, err := r.Standings(Scenario{})
+	if err != nil {
+		return nil, err
+	}
+
+	hypothetical, err := r.Standings(s)
+	if err != nil {
+		r.logger.Error("failed to evaluate scenario", zap.Error(err))
+		return nil, err
+	}
+
+	return results.Diff(results.NewRows(actual), results.NewRows(hypothetical)), nil
+}
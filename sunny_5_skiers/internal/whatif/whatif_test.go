@@ -0,0 +1,60 @@
+package whatif
+
+import (
+	"biathlon/config"
+	"biathlon/internal/validator"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const events = `[09:30:00.000] 1 1
+[09:30:00.000] 1 2
+[09:40:00.000] 2 1 10:00:00.000
+[09:40:00.000] 2 2 10:01:30.000
+[10:00:00.000] 4 1
+[10:01:30.000] 4 2
+[10:20:00.000] 10 1
+[10:21:00.000] 10 2`
+
+func TestRace(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Config{Laps: 1, LapLen: 3000, FiringLines: 1, StartDelta: "00:01:30"}
+	race, err := Read(zap.NewNop(), cfg, strings.NewReader(events))
+	require.NoError(t, err)
+
+	t.Run("no changes test", func(t *testing.T) {
+		t.Parallel()
+		changes, err := race.Compare(Scenario{})
+		require.NoError(t, err)
+		require.Empty(t, changes)
+	})
+
+	t.Run("edit test", func(t *testing.T) {
+		t.Parallel()
+		changes, err := race.Compare(Scenario{Edits: []validator.Edit{
+			{Line: 8, Event: "[10:22:00.000] 10 2"},
+		}})
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		require.Equal(t, -1, changes[1].RankChange)
+		require.Equal(t, "+00:01:00.000", changes[1].TimeDelta)
+
+		_, err = race.Compare(Scenario{Edits: []validator.Edit{{Line: 42}}})
+		require.Error(t, err)
+	})
+
+	t.Run("override test", func(t *testing.T) {
+		t.Parallel()
+		changes, err := race.Compare(Scenario{Overrides: map[string]string{"lapLen": "4000"}})
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		require.Equal(t, []string{"laps"}, changes[0].Fields)
+
+		_, err = race.Compare(Scenario{Overrides: map[string]string{"unknown": "1"}})
+		require.ErrorIs(t, err, config.ErrUnknownOption)
+		require.Equal(t, 3000, cfg.LapLen)
+	})
+}